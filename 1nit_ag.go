@@ -13,6 +13,19 @@ import (
 	"github.com/expgo/factory"
 )
 
+const (
+	// CallerFormatDefault is a CallerFormat of type default.
+	CallerFormatDefault CallerFormat = "default" // like [pkg.Func]dir/file.go:line
+	// CallerFormatShort is a CallerFormat of type short.
+	CallerFormatShort CallerFormat = "short" // like dir/file.go:line
+	// CallerFormatFull is a CallerFormat of type full.
+	CallerFormatFull CallerFormat = "full" // the full file path
+	// CallerFormatFunction is a CallerFormat of type function.
+	CallerFormatFunction CallerFormat = "function" // like pkg.Func
+	// CallerFormatFields is a CallerFormat of type fields.
+	CallerFormatFields CallerFormat = "fields" // separate file, line and function fields
+)
+
 const (
 	// ConsoleNo is a Console of type no.
 	ConsoleNo Console = "no"
@@ -22,6 +35,17 @@ const (
 	ConsoleStderr Console = "stderr"
 )
 
+const (
+	// DurationFormatString is a DurationFormat of type string.
+	DurationFormatString DurationFormat = "string"
+	// DurationFormatNanos is a DurationFormat of type nanos.
+	DurationFormatNanos DurationFormat = "nanos"
+	// DurationFormatMillis is a DurationFormat of type millis.
+	DurationFormatMillis DurationFormat = "millis"
+	// DurationFormatSeconds is a DurationFormat of type seconds.
+	DurationFormatSeconds DurationFormat = "seconds"
+)
+
 const (
 	// EncoderText is an Encoder of type text.
 	EncoderText Encoder = iota
@@ -48,6 +72,13 @@ const (
 	LevelInvalid Level = 6
 )
 
+const (
+	// LevelCaseLower is a LevelCase of type lower.
+	LevelCaseLower LevelCase = "lower"
+	// LevelCaseUpper is a LevelCase of type upper.
+	LevelCaseUpper LevelCase = "upper"
+)
+
 const (
 	// NameNo is a Name of type no.
 	NameNo Name = "no" // no name
@@ -57,10 +88,105 @@ const (
 	NameFull Name = "full" // with full path
 )
 
+const (
+	// OverflowBlock is an Overflow of type block.
+	OverflowBlock Overflow = "block" // wait for room in the queue
+	// OverflowDropNewest is an Overflow of type drop_newest.
+	OverflowDropNewest Overflow = "drop_newest" // drop the entry being written
+	// OverflowDropOldest is an Overflow of type drop_oldest.
+	OverflowDropOldest Overflow = "drop_oldest" // drop the oldest queued entry
+)
+
+const (
+	// RotationNo is a Rotation of type no.
+	RotationNo Rotation = "no" // size based rotation only
+	// RotationHourly is a Rotation of type hourly.
+	RotationHourly Rotation = "hourly" // one file per hour
+	// RotationDaily is a Rotation of type daily.
+	RotationDaily Rotation = "daily" // one file per day
+)
+
+const (
+	// SignalActionDebug is a SignalAction of type debug.
+	SignalActionDebug SignalAction = "debug" // raise all loggers to debug for a while
+	// SignalActionReload is a SignalAction of type reload.
+	SignalActionReload SignalAction = "reload" // reopen the files and reload the config
+	// SignalActionRotate is a SignalAction of type rotate.
+	SignalActionRotate SignalAction = "rotate" // rotate the files
+)
+
+const (
+	// SinkTypeConsole is a SinkType of type console.
+	SinkTypeConsole SinkType = "console"
+	// SinkTypeFile is a SinkType of type file.
+	SinkTypeFile SinkType = "file"
+)
+
 func init() {
 	factory.Factory[Logger](NewWithConfigPath).Params("self", "value:logging").CheckValid()
 }
 
+var ErrInvalidCallerFormat = errors.New("not a valid CallerFormat")
+
+var _CallerFormatNameMap = map[string]CallerFormat{
+	"default":  CallerFormatDefault,
+	"short":    CallerFormatShort,
+	"full":     CallerFormatFull,
+	"function": CallerFormatFunction,
+	"fields":   CallerFormatFields,
+}
+
+// Name is the attribute of CallerFormat.
+func (x CallerFormat) Name() string {
+	if v, ok := _CallerFormatNameMap[string(x)]; ok {
+		return string(v)
+	}
+	return fmt.Sprintf("CallerFormat(%s).Name", string(x))
+}
+
+// Val is the attribute of CallerFormat.
+func (x CallerFormat) Val() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x CallerFormat) IsValid() bool {
+	_, ok := _CallerFormatNameMap[string(x)]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (x CallerFormat) String() string {
+	return x.Name()
+}
+
+// ParseCallerFormat converts a string to a CallerFormat.
+func ParseCallerFormat(value string) (CallerFormat, error) {
+	if x, ok := _CallerFormatNameMap[value]; ok {
+		return x, nil
+	}
+	if x, ok := _CallerFormatNameMap[strings.ToLower(value)]; ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("%s is %w", value, ErrInvalidCallerFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x CallerFormat) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *CallerFormat) UnmarshalText(text []byte) error {
+	val, err := ParseCallerFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*x = val
+	return nil
+}
+
 var ErrInvalidConsole = errors.New("not a valid Console")
 
 var _ConsoleNameMap = map[string]Console{
@@ -120,6 +246,66 @@ func (x *Console) UnmarshalText(text []byte) error {
 	return nil
 }
 
+var ErrInvalidDurationFormat = errors.New("not a valid DurationFormat")
+
+var _DurationFormatNameMap = map[string]DurationFormat{
+	"string":  DurationFormatString,
+	"nanos":   DurationFormatNanos,
+	"millis":  DurationFormatMillis,
+	"seconds": DurationFormatSeconds,
+}
+
+// Name is the attribute of DurationFormat.
+func (x DurationFormat) Name() string {
+	if v, ok := _DurationFormatNameMap[string(x)]; ok {
+		return string(v)
+	}
+	return fmt.Sprintf("DurationFormat(%s).Name", string(x))
+}
+
+// Val is the attribute of DurationFormat.
+func (x DurationFormat) Val() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x DurationFormat) IsValid() bool {
+	_, ok := _DurationFormatNameMap[string(x)]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (x DurationFormat) String() string {
+	return x.Name()
+}
+
+// ParseDurationFormat converts a string to a DurationFormat.
+func ParseDurationFormat(value string) (DurationFormat, error) {
+	if x, ok := _DurationFormatNameMap[value]; ok {
+		return x, nil
+	}
+	if x, ok := _DurationFormatNameMap[strings.ToLower(value)]; ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("%s is %w", value, ErrInvalidDurationFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x DurationFormat) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *DurationFormat) UnmarshalText(text []byte) error {
+	val, err := ParseDurationFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*x = val
+	return nil
+}
+
 var ErrInvalidEncoder = errors.New("not a valid Encoder")
 
 var _EncoderName = "textjson"
@@ -273,6 +459,64 @@ func (x *Level) UnmarshalText(text []byte) error {
 	return nil
 }
 
+var ErrInvalidLevelCase = errors.New("not a valid LevelCase")
+
+var _LevelCaseNameMap = map[string]LevelCase{
+	"lower": LevelCaseLower,
+	"upper": LevelCaseUpper,
+}
+
+// Name is the attribute of LevelCase.
+func (x LevelCase) Name() string {
+	if v, ok := _LevelCaseNameMap[string(x)]; ok {
+		return string(v)
+	}
+	return fmt.Sprintf("LevelCase(%s).Name", string(x))
+}
+
+// Val is the attribute of LevelCase.
+func (x LevelCase) Val() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x LevelCase) IsValid() bool {
+	_, ok := _LevelCaseNameMap[string(x)]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (x LevelCase) String() string {
+	return x.Name()
+}
+
+// ParseLevelCase converts a string to a LevelCase.
+func ParseLevelCase(value string) (LevelCase, error) {
+	if x, ok := _LevelCaseNameMap[value]; ok {
+		return x, nil
+	}
+	if x, ok := _LevelCaseNameMap[strings.ToLower(value)]; ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("%s is %w", value, ErrInvalidLevelCase)
+}
+
+// MarshalText implements the text marshaller method.
+func (x LevelCase) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *LevelCase) UnmarshalText(text []byte) error {
+	val, err := ParseLevelCase(string(text))
+	if err != nil {
+		return err
+	}
+	*x = val
+	return nil
+}
+
 var ErrInvalidName = errors.New("not a valid Name")
 
 var _NameNameMap = map[string]Name{
@@ -331,3 +575,238 @@ func (x *Name) UnmarshalText(text []byte) error {
 	*x = val
 	return nil
 }
+
+var ErrInvalidOverflow = errors.New("not a valid Overflow")
+
+var _OverflowNameMap = map[string]Overflow{
+	"block":       OverflowBlock,
+	"drop_newest": OverflowDropNewest,
+	"drop_oldest": OverflowDropOldest,
+}
+
+// Name is the attribute of Overflow.
+func (x Overflow) Name() string {
+	if v, ok := _OverflowNameMap[string(x)]; ok {
+		return string(v)
+	}
+	return fmt.Sprintf("Overflow(%s).Name", string(x))
+}
+
+// Val is the attribute of Overflow.
+func (x Overflow) Val() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Overflow) IsValid() bool {
+	_, ok := _OverflowNameMap[string(x)]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (x Overflow) String() string {
+	return x.Name()
+}
+
+// ParseOverflow converts a string to an Overflow.
+func ParseOverflow(value string) (Overflow, error) {
+	if x, ok := _OverflowNameMap[value]; ok {
+		return x, nil
+	}
+	if x, ok := _OverflowNameMap[strings.ToLower(value)]; ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("%s is %w", value, ErrInvalidOverflow)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Overflow) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Overflow) UnmarshalText(text []byte) error {
+	val, err := ParseOverflow(string(text))
+	if err != nil {
+		return err
+	}
+	*x = val
+	return nil
+}
+
+var ErrInvalidRotation = errors.New("not a valid Rotation")
+
+var _RotationNameMap = map[string]Rotation{
+	"no":     RotationNo,
+	"hourly": RotationHourly,
+	"daily":  RotationDaily,
+}
+
+// Name is the attribute of Rotation.
+func (x Rotation) Name() string {
+	if v, ok := _RotationNameMap[string(x)]; ok {
+		return string(v)
+	}
+	return fmt.Sprintf("Rotation(%s).Name", string(x))
+}
+
+// Val is the attribute of Rotation.
+func (x Rotation) Val() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Rotation) IsValid() bool {
+	_, ok := _RotationNameMap[string(x)]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (x Rotation) String() string {
+	return x.Name()
+}
+
+// ParseRotation converts a string to a Rotation.
+func ParseRotation(value string) (Rotation, error) {
+	if x, ok := _RotationNameMap[value]; ok {
+		return x, nil
+	}
+	if x, ok := _RotationNameMap[strings.ToLower(value)]; ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("%s is %w", value, ErrInvalidRotation)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Rotation) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Rotation) UnmarshalText(text []byte) error {
+	val, err := ParseRotation(string(text))
+	if err != nil {
+		return err
+	}
+	*x = val
+	return nil
+}
+
+var ErrInvalidSignalAction = errors.New("not a valid SignalAction")
+
+var _SignalActionNameMap = map[string]SignalAction{
+	"debug":  SignalActionDebug,
+	"reload": SignalActionReload,
+	"rotate": SignalActionRotate,
+}
+
+// Name is the attribute of SignalAction.
+func (x SignalAction) Name() string {
+	if v, ok := _SignalActionNameMap[string(x)]; ok {
+		return string(v)
+	}
+	return fmt.Sprintf("SignalAction(%s).Name", string(x))
+}
+
+// Val is the attribute of SignalAction.
+func (x SignalAction) Val() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x SignalAction) IsValid() bool {
+	_, ok := _SignalActionNameMap[string(x)]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (x SignalAction) String() string {
+	return x.Name()
+}
+
+// ParseSignalAction converts a string to a SignalAction.
+func ParseSignalAction(value string) (SignalAction, error) {
+	if x, ok := _SignalActionNameMap[value]; ok {
+		return x, nil
+	}
+	if x, ok := _SignalActionNameMap[strings.ToLower(value)]; ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("%s is %w", value, ErrInvalidSignalAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x SignalAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *SignalAction) UnmarshalText(text []byte) error {
+	val, err := ParseSignalAction(string(text))
+	if err != nil {
+		return err
+	}
+	*x = val
+	return nil
+}
+
+var ErrInvalidSinkType = errors.New("not a valid SinkType")
+
+var _SinkTypeNameMap = map[string]SinkType{
+	"console": SinkTypeConsole,
+	"file":    SinkTypeFile,
+}
+
+// Name is the attribute of SinkType.
+func (x SinkType) Name() string {
+	if v, ok := _SinkTypeNameMap[string(x)]; ok {
+		return string(v)
+	}
+	return fmt.Sprintf("SinkType(%s).Name", string(x))
+}
+
+// Val is the attribute of SinkType.
+func (x SinkType) Val() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x SinkType) IsValid() bool {
+	_, ok := _SinkTypeNameMap[string(x)]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (x SinkType) String() string {
+	return x.Name()
+}
+
+// ParseSinkType converts a string to a SinkType.
+func ParseSinkType(value string) (SinkType, error) {
+	if x, ok := _SinkTypeNameMap[value]; ok {
+		return x, nil
+	}
+	if x, ok := _SinkTypeNameMap[strings.ToLower(value)]; ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("%s is %w", value, ErrInvalidSinkType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x SinkType) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *SinkType) UnmarshalText(text []byte) error {
+	val, err := ParseSinkType(string(text))
+	if err != nil {
+		return err
+	}
+	*x = val
+	return nil
+}
@@ -1,16 +1,27 @@
 package log
 
 import (
+	"context"
 	"github.com/expgo/config"
 	"github.com/expgo/structure"
 	"github.com/expgo/sync"
 	"github.com/gobwas/glob"
+	"go.uber.org/multierr"
 	"io"
 	"reflect"
+	"sort"
 	"time"
 )
 
-var logs = map[string]Logger{}
+// logKey identifies a registered logger: the same type gets one logger per
+// config section or config value.
+type logKey struct {
+	typePath string
+	cfgPath  string
+	cfg      *Config
+}
+
+var logs = map[logKey]*logger{}
 var logsLock = sync.NewRWMutex()
 
 const DefaultConfigPath = "logging"
@@ -24,15 +35,22 @@ type Logger interface {
 	Level() Level
 	SetLevel(lvl Level)
 	TemporarySetLevel(lvl Level, d time.Duration)
-	AddHook(func(level Level, t time.Time, name string, msg string))
+	AddHook(func(level Level, t time.Time, name string, msg string)) (remove func())
+	AddEntryHook(f EntryHook) (remove func())
 	Writer() io.Writer
 	Sync() error
+	Reload() error
+	Stats() Stats
+
+	With(keysAndValues ...any) Logger
+	Named(name string) Logger
 
 	Log(lvl Level, args ...any)
 	Debug(args ...any)
 	Info(args ...any)
 	Warn(args ...any)
 	Error(args ...any)
+	DPanic(args ...any)
 	Panic(args ...any)
 	Fatal(args ...any)
 
@@ -41,6 +59,7 @@ type Logger interface {
 	Infof(template string, args ...any)
 	Warnf(template string, args ...any)
 	Errorf(template string, args ...any)
+	DPanicf(template string, args ...any)
 	Panicf(template string, args ...any)
 	Fatalf(template string, args ...any)
 
@@ -49,8 +68,49 @@ type Logger interface {
 	Infow(msg string, keysAndValues ...any)
 	Warnw(msg string, keysAndValues ...any)
 	Errorw(msg string, keysAndValues ...any)
+	DPanicw(msg string, keysAndValues ...any)
 	Panicw(msg string, keysAndValues ...any)
 	Fatalw(msg string, keysAndValues ...any)
+
+	Logln(lvl Level, args ...any)
+	Debugln(args ...any)
+	Infoln(args ...any)
+	Warnln(args ...any)
+	Errorln(args ...any)
+	DPanicln(args ...any)
+	Panicln(args ...any)
+	Fatalln(args ...any)
+
+	LogCtx(ctx context.Context, lvl Level, msg string, keysAndValues ...any)
+	DebugCtx(ctx context.Context, msg string, keysAndValues ...any)
+	InfoCtx(ctx context.Context, msg string, keysAndValues ...any)
+	WarnCtx(ctx context.Context, msg string, keysAndValues ...any)
+	ErrorCtx(ctx context.Context, msg string, keysAndValues ...any)
+	DPanicCtx(ctx context.Context, msg string, keysAndValues ...any)
+	PanicCtx(ctx context.Context, msg string, keysAndValues ...any)
+	FatalCtx(ctx context.Context, msg string, keysAndValues ...any)
+}
+
+// Stats holds the counters of a logger.
+type Stats struct {
+	// Sampled is the number of entries dropped by sampling.
+	Sampled uint64
+	// Dropped is the number of entries dropped by full async sink queues.
+	Dropped uint64
+}
+
+// LoggerInfo describes a registered logger, see List.
+type LoggerInfo struct {
+	TypePath string `json:"typepath"`
+	// ConfigPath is the config section of the logger, empty for a logger built
+	// from a Config value.
+	ConfigPath string `json:"configpath,omitempty"`
+	// Level is the effective level, a temporary one while it is active.
+	Level Level `json:"level"`
+	// TemporaryUntil is when the active temporary level ends, nil without one.
+	TemporaryUntil *time.Time `json:"temporaryuntil,omitempty"`
+	// Sinks are the sinks the logger writes to.
+	Sinks []Sink `json:"sinks"`
 }
 
 func Log[T any]() Logger {
@@ -92,21 +152,29 @@ func getOrNewLog(t any, cfgPath string, cfg *Config) Logger {
 	return getOrNewLogByPath(typePath, cfgPath, cfg)
 }
 
+// getOrNewLogByPath returns the logger of the type for the config section, or
+// for the config when it is given instead of a section.
 func getOrNewLogByPath(typePath string, cfgPath string, cfg *Config) Logger {
+	key := logKey{typePath: typePath, cfgPath: cfgPath, cfg: cfg}
+
 	logsLock.RLock()
-	log, ok := logs[typePath]
+	log, ok := logs[key]
 	logsLock.RUnlock()
 
-	if !ok {
+	if ok {
+		return log
+	}
+
+	logsLock.Lock()
+	defer logsLock.Unlock()
+
+	if log, ok = logs[key]; !ok {
 		log = &logger{
 			typePath: typePath,
 			cfgPath:  cfgPath,
 			cfg:      cfg,
 		}
-
-		logsLock.Lock()
-		logs[typePath] = log
-		logsLock.Unlock()
+		logs[key] = log
 	}
 
 	return log
@@ -116,34 +184,113 @@ func SetDefaultLogFile(filename string) error {
 	return config.SetValue(filename, DefaultConfigPath, "file", "filename")
 }
 
-func SetLevel(logPathGlob string, level Level) {
-	TemporarySetLevel(logPathGlob, level, 0)
+// SetLevel sets the level of the loggers whose type path matches the glob,
+// returning an error for an invalid glob.
+func SetLevel(logPathGlob string, level Level) error {
+	return TemporarySetLevel(logPathGlob, level, 0)
 }
 
-func TemporarySetLevel(logPath string, level Level, d time.Duration) {
-	logPathGlob := glob.MustCompile(logPath)
+// TemporarySetLevel sets the level of the loggers whose type path matches the
+// glob for d, or until changed again when d is 0. An invalid glob is returned
+// as an error and no level changes.
+func TemporarySetLevel(logPath string, level Level, d time.Duration) error {
+	logPathGlob, err := compileGlob(logPath)
+	if err != nil {
+		return err
+	}
 
+	temporarySetLevel(logPathGlob, level, d)
+	return nil
+}
+
+func temporarySetLevel(logPathGlob glob.Glob, level Level, d time.Duration) {
 	logsLock.RLock()
 	_logs := structure.CloneMap(logs)
 	logsLock.RUnlock()
 
 	for key, log := range _logs {
-		if logPathGlob.Match(key) {
+		if logPathGlob.Match(key.typePath) {
 			log.TemporarySetLevel(level, d)
 		}
 	}
 }
 
-func Sync() error {
+// List describes every registered logger, sorted by type path and config
+// path. Loggers not used yet are built to report their level and sinks.
+func List() []LoggerInfo {
+	logsLock.RLock()
+	_logs := structure.CloneMap(logs)
+	logsLock.RUnlock()
+
+	infos := make([]LoggerInfo, 0, len(_logs))
+	for _, log := range _logs {
+		infos = append(infos, log.info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].TypePath != infos[j].TypePath {
+			return infos[i].TypePath < infos[j].TypePath
+		}
+		return infos[i].ConfigPath < infos[j].ConfigPath
+	})
+
+	return infos
+}
+
+// Explain tells, for each registered logger of the type, which level rule of
+// its config applies. See Config.Explain.
+func Explain(typePath string) []Explanation {
 	logsLock.RLock()
 	_logs := structure.CloneMap(logs)
 	logsLock.RUnlock()
 
+	explanations := []Explanation{}
 	for _, log := range _logs {
-		if err := log.Sync(); err != nil {
-			return err
+		if log.typePath != typePath {
+			continue
 		}
+
+		log.init()
+		explanation := log.getActive().Explain(typePath)
+		explanation.ConfigPath = log.cfgPath
+		explanations = append(explanations, explanation)
 	}
 
-	return nil
+	sort.Slice(explanations, func(i, j int) bool {
+		return explanations[i].ConfigPath < explanations[j].ConfigPath
+	})
+
+	return explanations
+}
+
+// Sync flushes every registered logger. All loggers are flushed even if some
+// fail; the errors are combined.
+func Sync() error {
+	logsLock.RLock()
+	_logs := structure.CloneMap(logs)
+	logsLock.RUnlock()
+
+	var err error
+	for _, log := range _logs {
+		err = multierr.Append(err, log.Sync())
+	}
+
+	return err
+}
+
+// Reload rebuilds every registered logger from its config section, so changes
+// made through config.SetConfig or config.SetValue take effect without a
+// restart. Loggers keep their hooks and in-flight calls finish on the old
+// sinks. All loggers are reloaded even if some fail; the errors are combined.
+func Reload() error {
+	logsLock.RLock()
+	_logs := structure.CloneMap(logs)
+	logsLock.RUnlock()
+
+	var err error
+	for _, log := range _logs {
+		err = multierr.Append(err, log.Reload())
+	}
+
+	return err
 }
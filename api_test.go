@@ -1,9 +1,15 @@
 package log
 
 import (
+	"bytes"
+	"context"
 	"github.com/expgo/config"
 	"github.com/expgo/factory"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -22,15 +28,53 @@ func (m *MyLog) RunLog() {
 	m.log.Error("error")
 }
 
+// newTestConfig resets the logger registry and returns a config that writes
+// only to filename, a text file in a temporary directory.
+func newTestConfig(t *testing.T) (cfg *Config, filename string) {
+	logs = map[logKey]*logger{}
+
+	filename = filepath.Join(t.TempDir(), "test.log")
+	cfg = factory.New[Config]()
+	cfg.Console.Stream = ConsoleNo
+	cfg.File.Filename = filename
+
+	return cfg, filename
+}
+
+// readLog syncs l and returns the content of filename.
+func readLog(t *testing.T, l Logger, filename string) string {
+	assert.NoError(t, l.Sync())
+
+	buf, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	return string(buf)
+}
+
+// redirectStderr points os.Stderr to a file until the test ends and returns
+// the file name.
+func redirectStderr(t *testing.T) string {
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr.log"))
+	assert.NoError(t, err)
+
+	origStderr := os.Stderr
+	os.Stderr = stderr
+	t.Cleanup(func() {
+		os.Stderr = origStderr
+		_ = stderr.Close()
+	})
+
+	return stderr.Name()
+}
+
 func TestLog(t *testing.T) {
-	logs = map[string]Logger{}
+	logs = map[logKey]*logger{}
 
 	log := Log[MyLogStruct]()
 	log.Info("hello")
 }
 
 func TestLevel(t *testing.T) {
-	logs = map[string]Logger{}
+	logs = map[logKey]*logger{}
 
 	cfg := factory.New[Config]()
 	_ = config.GetConfig(cfg)
@@ -65,7 +109,7 @@ func TestLevel(t *testing.T) {
 }
 
 func TestLogRoll(t *testing.T) {
-	logs = map[string]Logger{}
+	logs = map[logKey]*logger{}
 
 	cfg := factory.New[Config]()
 	_ = config.GetConfig(cfg)
@@ -82,7 +126,7 @@ func TestLogRoll(t *testing.T) {
 }
 
 func TestChangeLevel(t *testing.T) {
-	logs = map[string]Logger{}
+	logs = map[logKey]*logger{}
 
 	log := Log[MyLogStruct]()
 
@@ -118,7 +162,7 @@ func TestChangeLevel(t *testing.T) {
 }
 
 func TestLogWire(t *testing.T) {
-	logs = map[string]Logger{}
+	logs = map[logKey]*logger{}
 
 	myLog := factory.New[MyLog]()
 	msgs := []string{}
@@ -129,3 +173,361 @@ func TestLogWire(t *testing.T) {
 	expectMsgs := []string{"info", "warn", "error"}
 	assert.Equal(t, expectMsgs, msgs)
 }
+
+func TestReload(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	assert.NoError(t, config.SetConfig(map[string]any{"level": map[string]any{"*": "info"}}, "reload"))
+
+	log := LogWithConfigPath[MyLogStruct]("reload")
+
+	msgs := []string{}
+	log.AddHook(func(level Level, t time.Time, name string, msg string) {
+		msgs = append(msgs, msg)
+	})
+
+	log.Debug("debug hello 1")
+	assert.Equal(t, LevelInfo, log.Level())
+
+	assert.NoError(t, config.SetConfig(map[string]any{"level": map[string]any{"*": "debug"}}, "reload"))
+	assert.NoError(t, Reload())
+
+	log.Debug("debug hello 2")
+	assert.Equal(t, LevelDebug, log.Level())
+
+	assert.Equal(t, []string{"debug hello 2"}, msgs)
+}
+
+func TestSharedFileSink(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	cfg := factory.New[Config]()
+	cfg.Console.Stream = ConsoleNo
+	cfg.File.Filename = "log/shared.log"
+
+	structLog := LogWithConfig[MyLogStruct](cfg)
+	log := LogWithConfig[MyLog](cfg)
+	structLog.Info("struct log")
+	log.Info("log")
+
+	filename, err := filepath.Abs(cfg.File.Filename)
+	assert.NoError(t, err)
+	sharedSink := func() *fileSink {
+		for key, sink := range fileSinks {
+			if key.filename == filename {
+				return sink
+			}
+		}
+		return nil
+	}
+
+	sink := sharedSink()
+	assert.NotNil(t, sink)
+	assert.Len(t, structLog.(*logger).releases, 1)
+	assert.Len(t, log.(*logger).releases, 1)
+	assert.Equal(t, 2, sink.refs)
+
+	assert.NoError(t, Reload())
+	assert.Same(t, sink, sharedSink())
+	assert.Equal(t, 2, sink.refs)
+}
+
+func TestWithAndNamed(t *testing.T) {
+	cfg, filename := newTestConfig(t)
+	cfg.File.Encoder = EncoderJson
+
+	log := LogWithConfig[MyLogStruct](cfg)
+
+	names := []string{}
+	log.AddHook(func(level Level, t time.Time, name string, msg string) {
+		names = append(names, name)
+	})
+
+	child := log.With("request_id", "r1").Named("child")
+	child.With("tenant", "t1").Infow("hello", "k", "v")
+	child.Debug("debug hello")
+
+	log.SetLevel(LevelDebug)
+	child.Debug("debug hello")
+
+	assert.Equal(t, LevelDebug, child.Level())
+	assert.Equal(t, []string{"log.MyLogStruct.child", "log.MyLogStruct.child"}, names)
+
+	assert.Contains(t, readLog(t, log, filename), `"request_id":"r1","tenant":"t1","k":"v"`)
+}
+
+type traceIDKey struct{}
+
+func TestContextLog(t *testing.T) {
+	defer AddContextExtractor(func(ctx context.Context) []any {
+		if traceID, ok := ctx.Value(traceIDKey{}).(string); ok {
+			return []any{"trace_id", traceID}
+		}
+		return nil
+	})()
+
+	cfg, filename := newTestConfig(t)
+	cfg.File.Encoder = EncoderJson
+
+	ctx := WithContext(context.WithValue(context.Background(), traceIDKey{}, "t1"), LogWithConfig[MyLogStruct](cfg))
+
+	log := FromContext(ctx)
+	assert.NotNil(t, log)
+	assert.Nil(t, FromContext(context.Background()))
+
+	log.InfoCtx(ctx, "hello", "k", "v")
+
+	assert.Contains(t, readLog(t, log, filename), `"msg":"hello","trace_id":"t1","k":"v"`)
+}
+
+func TestEntryHook(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	log := Log[MyLogStruct]()
+
+	entries := []Entry{}
+	remove := log.AddEntryHook(func(entry Entry) {
+		entries = append(entries, entry)
+	})
+
+	log.With("request_id", "r1").Errorw("error hello", "error", "failed", "count", 2)
+	remove()
+	log.Error("error hello 2")
+
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, LevelError, entry.Level)
+	assert.Equal(t, "error hello", entry.Message)
+	assert.Equal(t, "github.com/expgo/log.MyLogStruct", entry.TypePath)
+	assert.Equal(t, map[string]any{"request_id": "r1", "error": "failed", "count": int64(2)}, entry.Fields)
+	assert.True(t, entry.Caller.Defined)
+	assert.Contains(t, entry.Caller.File, "api_test.go")
+}
+
+func TestConcurrentHooks(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+
+	before := LogWithConfig[MyLogStruct](cfg)
+
+	var globalCount atomic.Int64
+	removeGlobal := AddGlobalHook(func(entry Entry) {
+		globalCount.Add(1)
+	})
+	defer removeGlobal()
+
+	after := LogWithConfig[MyLog](cfg)
+
+	wg := gosync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				before.Info("before")
+				after.Info("after")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				remove := before.AddHook(func(level Level, t time.Time, name string, msg string) {})
+				remove()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(800), globalCount.Load())
+
+	removeGlobal()
+	before.Info("removed")
+	assert.Equal(t, int64(800), globalCount.Load())
+}
+
+func TestDPanic(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+
+	log := LogWithConfig[MyLogStruct](cfg)
+
+	msgs := []string{}
+	log.AddHook(func(level Level, t time.Time, name string, msg string) {
+		msgs = append(msgs, msg)
+	})
+
+	assert.NotPanics(t, func() { log.DPanic("dpanic", "prod") })
+	log.Infoln("info", "ln")
+
+	cfg.Development = true
+	assert.NoError(t, log.Reload())
+	assert.Panics(t, func() { log.DPanicw("dpanic dev") })
+
+	assert.Equal(t, []string{"dpanicprod", "info ln", "dpanic dev"}, msgs)
+}
+
+func TestSinkLevel(t *testing.T) {
+	cfg, filename := newTestConfig(t)
+	cfg.File.Level = LevelWarn
+
+	log := LogWithConfig[MyLogStruct](cfg)
+	log.SetLevel(LevelDebug)
+	log.Debug("debug hello")
+	log.Info("info hello")
+	log.Warn("warn hello")
+
+	log.SetLevel(LevelError)
+	log.Warn("warn hello 2")
+
+	out := readLog(t, log, filename)
+	assert.NotContains(t, out, "debug hello")
+	assert.NotContains(t, out, "info hello")
+	assert.Contains(t, out, "warn hello")
+	assert.NotContains(t, out, "warn hello 2")
+}
+
+func TestSinks(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	dir := t.TempDir()
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"console": map[string]any{"stream": "no"},
+		"sinks": []any{
+			map[string]any{"type": "file", "encoder": "json", "filename": filepath.Join(dir, "app.json")},
+			map[string]any{"type": "file", "filename": filepath.Join(dir, "app.log")},
+			map[string]any{"name": "errors", "type": "file", "level": "error", "filename": filepath.Join(dir, "error.log")},
+		},
+	}, "sinks"))
+
+	log := LogWithConfigPath[MyLogStruct]("sinks")
+	log.Info("info hello")
+	log.Error("error hello")
+	assert.NoError(t, log.Sync())
+
+	read := func(name string) string {
+		buf, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(buf)
+	}
+
+	assert.Contains(t, read("app.json"), `"msg":"info hello"`)
+	assert.Contains(t, read("app.log"), "\tinfo hello\n")
+	assert.NotContains(t, read("error.log"), "info hello")
+	assert.Contains(t, read("error.log"), "error hello")
+
+	stderr := redirectStderr(t)
+	cfg := factory.New[Config]()
+	cfg.Sinks = []Sink{{Name: "bad", Type: SinkTypeFile}}
+	bad := NewWithTypePathAndConfig("bad", cfg)
+	bad.Info("bad")
+	assert.EqualError(t, bad.Reload(), "sink 'bad' has no filename")
+
+	out := readLog(t, bad, stderr)
+	assert.Contains(t, out, "log: bad: sink 'bad' has no filename, writing to stderr\n")
+	assert.Contains(t, out, "\tbad\n")
+}
+
+func TestAsyncSink(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	filename := filepath.Join(t.TempDir(), "async.log")
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"console": map[string]any{"stream": "no"},
+		"file": map[string]any{
+			"filename": filename,
+			"async":    map[string]any{"buffersize": 100, "flushinterval": "1h", "overflow": "drop_newest"},
+		},
+	}, "async"))
+
+	log := LogWithConfigPath[MyLogStruct]("async")
+	for i := 0; i < 10; i++ {
+		log.Infof("hello %d", i)
+	}
+
+	buf, err := os.ReadFile(filename)
+	assert.True(t, len(buf) == 0 || os.IsNotExist(err))
+
+	assert.NoError(t, Sync())
+	buf, err = os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, bytes.Count(buf, []byte("\n")))
+	assert.Equal(t, Stats{}, log.Stats())
+
+	log.Info("before reload")
+	assert.NoError(t, Reload())
+	log.Info("after reload")
+
+	assert.NoError(t, Sync())
+	buf, err = os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, bytes.Count(buf, []byte("\n")))
+}
+
+func TestRegistry(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	assert.NoError(t, config.SetConfig(map[string]any{"level": map[string]any{"*": "warn"}}, "registry1"))
+	assert.NoError(t, config.SetConfig(map[string]any{"level": map[string]any{"*": "error"}}, "registry2"))
+
+	log1 := LogWithConfigPath[MyLogStruct]("registry1")
+	log2 := LogWithConfigPath[MyLogStruct]("registry2")
+	assert.NotSame(t, log1, log2)
+	assert.Same(t, log1, LogWithConfigPath[MyLogStruct]("registry1"))
+	assert.Same(t, log1, NewWithTypePathAndConfigPath("github.com/expgo/log.MyLogStruct", "registry1"))
+	assert.Equal(t, LevelWarn, log1.Level())
+	assert.Equal(t, LevelError, log2.Level())
+
+	cfg1 := factory.New[Config]()
+	cfg1.Level["*"] = LevelDebug
+	cfg2 := factory.New[Config]()
+
+	cfgLog1 := LogWithConfig[MyLogStruct](cfg1)
+	cfgLog2 := LogWithConfig[MyLogStruct](cfg2)
+	assert.NotSame(t, cfgLog1, cfgLog2)
+	assert.Same(t, cfgLog1, LogWithConfig[MyLogStruct](cfg1))
+	assert.Equal(t, LevelDebug, cfgLog1.Level())
+	assert.Equal(t, LevelInfo, cfgLog2.Level())
+
+	assert.NoError(t, SetLevel("*MyLogStruct", LevelFatal))
+	for _, log := range []Logger{log1, log2, cfgLog1, cfgLog2} {
+		assert.Equal(t, LevelFatal, log.Level())
+	}
+
+	assert.ErrorContains(t, TemporarySetLevel("[", LevelDebug, time.Hour), "invalid glob '['")
+	assert.Equal(t, LevelFatal, log1.Level())
+}
+
+func TestList(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"level":   map[string]any{"*": "warn"},
+		"console": map[string]any{"stream": "stderr"},
+	}, "list"))
+
+	cfg := factory.New[Config]()
+	cfg.Console.Stream = ConsoleNo
+	cfg.File.Filename = filepath.Join(t.TempDir(), "list.log")
+
+	LogWithConfigPath[MyLogStruct]("list")
+	log := LogWithConfig[MyLog](cfg)
+	log.TemporarySetLevel(LevelDebug, time.Hour)
+	defer log.TemporarySetLevel(LevelInfo, 0)
+
+	infos := List()
+	assert.Len(t, infos, 2)
+
+	assert.Equal(t, "github.com/expgo/log.MyLog", infos[0].TypePath)
+	assert.Empty(t, infos[0].ConfigPath)
+	assert.Equal(t, LevelDebug, infos[0].Level)
+	assert.NotNil(t, infos[0].TemporaryUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *infos[0].TemporaryUntil, time.Minute)
+	assert.Len(t, infos[0].Sinks, 1)
+	assert.Equal(t, SinkTypeFile, infos[0].Sinks[0].Type)
+	assert.Equal(t, cfg.File.Filename, infos[0].Sinks[0].Filename)
+
+	assert.Equal(t, "github.com/expgo/log.MyLogStruct", infos[1].TypePath)
+	assert.Equal(t, "list", infos[1].ConfigPath)
+	assert.Equal(t, LevelWarn, infos[1].Level)
+	assert.Nil(t, infos[1].TemporaryUntil)
+	assert.Len(t, infos[1].Sinks, 1)
+	assert.Equal(t, ConsoleStderr, infos[1].Sinks[0].Stream)
+}
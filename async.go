@@ -0,0 +1,212 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultAsync matches the value tags of Async. Async blocks of sinks do not
+// get the tag defaults and fall back to it for empty values.
+var defaultAsync = Async{
+	BufferSize:    1024,
+	FlushInterval: 100 * time.Millisecond,
+	Overflow:      OverflowBlock,
+}
+
+// asyncWriter queues the encoded entries of a sink and writes them from its
+// own goroutine, in batches every flush interval or once the queue is full.
+type asyncWriter struct {
+	out      zapcore.WriteSyncer
+	size     int
+	overflow Overflow
+	dropped  *atomic.Uint64
+
+	lock     sync.Mutex
+	notFull  *sync.Cond
+	flushed  *sync.Cond
+	queue    [][]byte
+	queued   uint64 // entries taken into the queue
+	finished uint64 // entries written or dropped from the queue
+	err      error
+	closed   bool
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newAsyncWriter(out zapcore.WriteSyncer, async *Async, dropped *atomic.Uint64) *asyncWriter {
+	size := async.BufferSize
+	if size <= 0 {
+		size = defaultAsync.BufferSize
+	}
+
+	interval := async.FlushInterval
+	if interval <= 0 {
+		interval = defaultAsync.FlushInterval
+	}
+
+	overflow := async.Overflow
+	if len(overflow) == 0 {
+		overflow = defaultAsync.Overflow
+	}
+
+	w := &asyncWriter{
+		out:      out,
+		size:     size,
+		overflow: overflow,
+		dropped:  dropped,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.lock)
+	w.flushed = sync.NewCond(&w.lock)
+
+	go w.run(interval)
+
+	return w
+}
+
+// Write queues a copy of p, applying the overflow policy when the queue is
+// full. Writes after Close go to the wrapped writer directly.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+
+	if w.closed {
+		w.lock.Unlock()
+		return w.out.Write(p)
+	}
+
+	for len(w.queue) >= w.size && !w.closed {
+		w.signal()
+
+		switch w.overflow {
+		case OverflowDropNewest:
+			w.lock.Unlock()
+			w.dropped.Add(1)
+			return len(p), nil
+		case OverflowDropOldest:
+			w.queue[0] = nil
+			w.queue = w.queue[1:]
+			w.finished++
+			w.dropped.Add(1)
+			w.flushed.Broadcast()
+		default:
+			w.notFull.Wait()
+		}
+	}
+
+	if w.closed {
+		w.lock.Unlock()
+		return w.out.Write(p)
+	}
+
+	w.queue = append(w.queue, append([]byte(nil), p...))
+	w.queued++
+	if len(w.queue) >= w.size {
+		w.signal()
+	}
+
+	w.lock.Unlock()
+	return len(p), nil
+}
+
+// Sync waits until the entries queued before the call are written, then syncs
+// the wrapped writer.
+func (w *asyncWriter) Sync() error {
+	w.lock.Lock()
+	target := w.queued
+	w.signal()
+	for w.finished < target {
+		w.flushed.Wait()
+	}
+	err := w.err
+	w.err = nil
+	w.lock.Unlock()
+
+	return multierr.Append(err, w.out.Sync())
+}
+
+// Close writes the queued entries and stops the goroutine of the writer.
+func (w *asyncWriter) Close() error {
+	w.lock.Lock()
+	if w.closed {
+		w.lock.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.notFull.Broadcast()
+	w.lock.Unlock()
+
+	close(w.stop)
+	<-w.done
+
+	w.lock.Lock()
+	err := w.err
+	w.err = nil
+	w.lock.Unlock()
+
+	return multierr.Append(err, w.out.Sync())
+}
+
+// signal wakes the goroutine to write the queue now. The caller holds the lock.
+func (w *asyncWriter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *asyncWriter) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.wake:
+		case <-ticker.C:
+		case <-w.stop:
+			w.flush()
+			return
+		}
+
+		w.flush()
+	}
+}
+
+// flush writes the queued entries as one batch.
+func (w *asyncWriter) flush() {
+	w.lock.Lock()
+	batch := w.queue
+	w.queue = nil
+	w.notFull.Broadcast()
+	w.lock.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	size := 0
+	for _, p := range batch {
+		size += len(p)
+	}
+	buf := make([]byte, 0, size)
+	for _, p := range batch {
+		buf = append(buf, p...)
+	}
+
+	_, err := w.out.Write(buf)
+
+	w.lock.Lock()
+	w.finished += uint64(len(batch))
+	w.err = multierr.Append(w.err, err)
+	w.flushed.Broadcast()
+	w.lock.Unlock()
+}
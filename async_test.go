@@ -0,0 +1,119 @@
+package log
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gateWriter blocks the first write until the gate is opened.
+type gateWriter struct {
+	lock    sync.Mutex
+	buf     bytes.Buffer
+	entered chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.entered)
+		<-w.gate
+	})
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) Sync() error {
+	return nil
+}
+
+func (w *gateWriter) String() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	tests := []struct {
+		overflow Overflow
+		want     string
+		dropped  uint64
+	}{
+		{OverflowBlock, "abcde", 0},
+		{OverflowDropNewest, "abcd", 1},
+		{OverflowDropOldest, "abde", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.overflow.String(), func(t *testing.T) {
+			out := newGateWriter()
+			dropped := atomic.Uint64{}
+			w := newAsyncWriter(out, &Async{BufferSize: 2, FlushInterval: time.Hour, Overflow: tt.overflow}, &dropped)
+
+			write := func(s string) {
+				_, err := w.Write([]byte(s))
+				assert.NoError(t, err)
+			}
+
+			// a full queue is written at once, the writer then hangs on the gate
+			write("a")
+			write("b")
+			<-out.entered
+
+			write("c")
+			write("d")
+
+			written := make(chan struct{})
+			go func() {
+				write("e")
+				close(written)
+			}()
+
+			if tt.overflow == OverflowBlock {
+				select {
+				case <-written:
+					t.Fatal("write did not block on a full queue")
+				case <-time.After(50 * time.Millisecond):
+				}
+			} else {
+				<-written
+			}
+
+			close(out.gate)
+			<-written
+
+			assert.NoError(t, w.Sync())
+			assert.Equal(t, tt.want, out.String())
+			assert.Equal(t, tt.dropped, dropped.Load())
+
+			assert.NoError(t, w.Close())
+			write("f")
+			assert.Equal(t, tt.want+"f", out.String())
+		})
+	}
+}
+
+func TestAsyncWriterFlushInterval(t *testing.T) {
+	out := newGateWriter()
+	close(out.gate)
+	dropped := atomic.Uint64{}
+	w := newAsyncWriter(out, &Async{FlushInterval: 10 * time.Millisecond}, &dropped)
+	defer w.Close()
+
+	_, err := w.Write([]byte("hello"))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return out.String() == "hello"
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/expgo/structure"
+	"go.uber.org/multierr"
+)
+
+// closed is set by Close, loggers built afterwards write to stderr.
+var closed atomic.Bool
+
+// Close flushes and closes every registered logger for a shutdown: temporary
+// levels are rolled back, dedup summaries and async queues written, and the
+// files closed, including shared files still open. Loggers stay usable and
+// write to stderr afterwards. The errors of all loggers are combined; when ctx
+// is done first, Close returns its error and the closing goes on in the
+// background. Loggers first used after Close write to stderr too.
+func Close(ctx context.Context) error {
+	closed.Store(true)
+
+	done := make(chan error, 1)
+
+	go func() {
+		logsLock.RLock()
+		_logs := structure.CloneMap(logs)
+		logsLock.RUnlock()
+
+		var err error
+		for _, log := range _logs {
+			err = multierr.Append(err, log.close())
+		}
+
+		done <- multierr.Append(err, closeFileSinks())
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	cfg, filename := newTestConfig(t)
+	cfg.File.Async = &Async{FlushInterval: time.Hour}
+	cfg.Dedup = map[string]*Dedup{"*": {Window: time.Hour}}
+
+	log := LogWithConfig[MyLogStruct](cfg)
+	unused := LogWithConfig[MyLog](cfg)
+	log.TemporarySetLevel(LevelDebug, time.Hour)
+	log.Debug("debug hello")
+	log.Info("info hello")
+	log.Info("info hello")
+	child := log.With("k", 1)
+	for i := 0; i < 3; i++ {
+		child.Info("child hello")
+	}
+
+	stderr := redirectStderr(t)
+
+	defer closed.Store(false)
+	assert.NoError(t, Close(context.Background()))
+
+	buf, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, bytes.Count(buf, []byte("\n")))
+	assert.Contains(t, string(buf), "debug hello")
+	assert.Contains(t, string(buf), `info hello	{"repeated": 1}`)
+	assert.Contains(t, string(buf), `child hello	{"k": 1, "repeated": 2}`)
+	assert.Empty(t, fileSinks)
+	assert.Equal(t, LevelInfo, log.Level())
+
+	log.Info("after close")
+	unused.Info("unused after close")
+	created := NewWithTypePathAndConfig("github.com/expgo/log.AfterClose", cfg)
+	created.Info("created after close")
+	assert.NoError(t, Sync())
+	assert.Empty(t, fileSinks)
+
+	buf, err = os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(buf), "after close")
+
+	buf, err = os.ReadFile(stderr)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf), "after close")
+	assert.Contains(t, string(buf), "unused after close")
+	assert.Contains(t, string(buf), "created after close")
+}
+
+func TestCloseStaleRelease(t *testing.T) {
+	file := &FileLog{Filename: filepath.Join(t.TempDir(), "stale.log")}
+
+	stale, err := acquireFileSink(file)
+	assert.NoError(t, err)
+	assert.NoError(t, closeFileSinks())
+
+	current, err := acquireFileSink(file)
+	assert.NoError(t, err)
+	assert.NotSame(t, stale, current)
+
+	assert.NoError(t, stale.release())
+	assert.Same(t, current, fileSinks[current.key])
+
+	assert.NoError(t, current.release())
+	assert.Empty(t, fileSinks)
+}
@@ -2,8 +2,11 @@ package log
 
 import (
 	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"time"
 
-	"github.com/gobwas/glob"
+	"github.com/expgo/structure"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -39,6 +42,73 @@ Name is en enum
 */
 type Name string
 
+/*
+Rotation is an enum
+
+	@Enum {
+		no      // size based rotation only
+		hourly  // one file per hour
+		daily   // one file per day
+	}
+*/
+type Rotation string
+
+/*
+SinkType is an enum
+
+	@Enum {
+		console
+		file
+	}
+*/
+type SinkType string
+
+/*
+LevelCase is an enum
+
+	@Enum {
+		lower
+		upper
+	}
+*/
+type LevelCase string
+
+/*
+DurationFormat is an enum
+
+	@Enum {
+		string   // like 1.5s
+		nanos    // integer nanoseconds
+		millis   // integer milliseconds
+		seconds  // float seconds
+	}
+*/
+type DurationFormat string
+
+/*
+CallerFormat is an enum
+
+	@Enum {
+		default   // like [pkg.Func]dir/file.go:line
+		short     // like dir/file.go:line
+		full      // the full file path
+		function  // like pkg.Func
+		fields    // separate file, line and function fields
+	}
+*/
+type CallerFormat string
+
+/*
+Overflow is an enum
+
+	@Enum {
+		block        // wait for room in the queue
+		drop_newest  // drop the entry being written
+		drop_oldest  // drop the oldest queued entry
+	}
+*/
+type Overflow string
+
 /*
 Level is an enum
 
@@ -60,6 +130,48 @@ func (l Level) ToZapLevel() zapcore.Level {
 	return zapcore.Level(l)
 }
 
+// Encoding configures the keys and value formats of the encoded entries.
+//
+// In the encoding of a sink, empty values inherit the encoding of the config
+// section, which falls back to the defaults below. A key set to "-" is left
+// out of the entries.
+type Encoding struct {
+	TimeKey       string `json:"timekey" yaml:"timekey" value:"time"`
+	LevelKey      string `json:"levelkey" yaml:"levelkey" value:"level"`
+	NameKey       string `json:"namekey" yaml:"namekey" value:"logger"`
+	CallerKey     string `json:"callerkey" yaml:"callerkey" value:"caller"`
+	MessageKey    string `json:"messagekey" yaml:"messagekey" value:"msg"`
+	StacktraceKey string `json:"stacktracekey" yaml:"stacktracekey" value:"stack"`
+
+	// TimeFormat is a time layout like "2006-01-02T15:04:05.000000", or one of
+	// rfc3339, rfc3339nano, iso8601, epoch, epochmillis and epochnanos.
+	TimeFormat string `json:"timeformat" yaml:"timeformat" value:"2006-01-02T15:04:05.000000"`
+
+	// TimeZone is "Local", "UTC" or an IANA zone name like "Asia/Shanghai".
+	TimeZone string `json:"timezone" yaml:"timezone" value:"Local"`
+
+	LevelCase LevelCase      `json:"levelcase" yaml:"levelcase" value:"lower"`
+	Duration  DurationFormat `json:"duration" yaml:"duration" value:"string"`
+
+	// Caller is the format of the caller. The fields format writes the file,
+	// line and function fields instead of the caller key, which suits json.
+	Caller CallerFormat `json:"caller" yaml:"caller" value:"default"`
+}
+
+// Async makes a sink queue the encoded entries and write them from its own
+// goroutine, so a slow disk or terminal does not stall the logging calls.
+type Async struct {
+	// BufferSize is the number of entries the queue holds.
+	BufferSize int `json:"buffersize" yaml:"buffersize" value:"1024"`
+
+	// FlushInterval is the longest time entries wait in the queue. A full
+	// queue and Sync write them at once.
+	FlushInterval time.Duration `json:"flushinterval" yaml:"flushinterval" value:"100ms"`
+
+	// Overflow is what a write does when the queue is full.
+	Overflow Overflow `json:"overflow" yaml:"overflow" value:"block"`
+}
+
 type FileLog struct {
 	// Filename is the file to write logs to.  Backup log files will be retained
 	// in the same directory.  It uses <processname>-lumberjack.log in
@@ -92,20 +204,177 @@ type FileLog struct {
 	Compress bool `json:"compress" yaml:"compress"  value:"true"`
 	// contains filtered or unexported fields
 
+	// Rotation starts a new file every hour or day, named after the period
+	// start like app-2026-10-18.log or app-2026-10-18-15.log. MaxSize still
+	// rotates inside a period, and MaxAge and MaxBackups apply to the period
+	// files too. The period follows LocalTime. The default is no time rotation.
+	Rotation Rotation `json:"rotation" yaml:"rotation" value:"no"`
+
 	Encoder Encoder `json:"encoder" yaml:"encoder" value:"text"`
+
+	// Level is the minimum level written to the file, applied on top of the
+	// per-type level. The default debug lets the per-type level decide.
+	Level Level `json:"level" yaml:"level" value:"debug"`
+
+	// Encoding overrides the encoding of the config section for the file.
+	Encoding *Encoding `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+
+	// Async queues the writes to the file, unset writes synchronously.
+	Async *Async `json:"async,omitempty" yaml:"async,omitempty"`
 }
 
 type ConsoleLog struct {
 	Stream  Console `json:"stream" yaml:"stream" value:"stdout"`
 	Encoder Encoder `json:"encoder" yaml:"encoder" value:"text"`
+	// Level is the minimum level written to the console, applied on top of the
+	// per-type level. The default debug lets the per-type level decide.
+	Level Level `json:"level" yaml:"level" value:"debug"`
+	// Encoding overrides the encoding of the config section for the console.
+	Encoding *Encoding `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	// Async queues the writes to the console, unset writes synchronously.
+	Async *Async `json:"async,omitempty" yaml:"async,omitempty"`
+}
+
+// Sink is one output listed under `sinks`. The console and file keys of a
+// Config are shorthands for a console and a file sink written before them.
+//
+// Sinks in the list do not get the defaults of ConsoleLog and FileLog: an unset
+// stream writes to stdout, and unset file options use lumberjack's defaults.
+type Sink struct {
+	// Name identifies the sink, it defaults to the sink type.
+	Name    string   `json:"name" yaml:"name"`
+	Type    SinkType `json:"type" yaml:"type"`
+	Encoder Encoder  `json:"encoder" yaml:"encoder"`
+
+	// Level is the minimum level written to the sink, applied on top of the
+	// per-type level. Unset lets the per-type level decide.
+	Level *Level `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// Encoding overrides the encoding of the config section for the sink.
+	Encoding *Encoding `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+
+	// Async queues the writes of the sink, unset writes synchronously.
+	Async *Async `json:"async,omitempty" yaml:"async,omitempty"`
+
+	// Stream is the stream a console sink writes to.
+	Stream Console `json:"stream,omitempty" yaml:"stream,omitempty"`
+
+	// Filename, MaxSize, MaxAge, MaxBackups, LocalTime, Compress and Rotation
+	// configure a file sink, see FileLog.
+	Filename   string   `json:"filename,omitempty" yaml:"filename,omitempty"`
+	MaxSize    int      `json:"maxsize,omitempty" yaml:"maxsize,omitempty"`
+	MaxAge     int      `json:"maxage,omitempty" yaml:"maxage,omitempty"`
+	MaxBackups int      `json:"maxbackups,omitempty" yaml:"maxbackups,omitempty"`
+	LocalTime  bool     `json:"localtime,omitempty" yaml:"localtime,omitempty"`
+	Compress   bool     `json:"compress,omitempty" yaml:"compress,omitempty"`
+	Rotation   Rotation `json:"rotation,omitempty" yaml:"rotation,omitempty"`
 }
 
+func init() {
+	// list items parsed from yaml are interfaces holding a map, which the config
+	// mapping does not unwrap on its own
+	structure.RegisterMapper[any, Sink](func(from reflect.Value, to reflect.Value, option *structure.Option) error {
+		return structure.MapToValueWithOption(from.Interface(), to, option)
+	})
+}
+
+func (s *Sink) GetName() string {
+	if len(s.Name) > 0 {
+		return s.Name
+	}
+
+	return s.Type.String()
+}
+
+func (s *Sink) GetLevel() Level {
+	if s.Level == nil {
+		return LevelDebug
+	}
+
+	return *s.Level
+}
+
+func (s *Sink) GetStream() Console {
+	if len(s.Stream) == 0 {
+		return ConsoleStdout
+	}
+
+	return s.Stream
+}
+
+func (s *Sink) fileLog() *FileLog {
+	return &FileLog{
+		Filename:   s.Filename,
+		MaxSize:    s.MaxSize,
+		MaxAge:     s.MaxAge,
+		MaxBackups: s.MaxBackups,
+		LocalTime:  s.LocalTime,
+		Compress:   s.Compress,
+		Rotation:   s.Rotation,
+	}
+}
+
+// Stacktrace configures when entries carry the stack of the logging goroutine.
+type Stacktrace struct {
+	// Level maps type path globs to the minimum level capturing a stack, like
+	// the Level of a Config. It defaults to error for all types, invalid turns
+	// stacks off.
+	Level map[string]Level `json:"level" yaml:"level"`
+
+	// Trim drops the frames of this package and of the runtime from the stack.
+	Trim bool `json:"trim" yaml:"trim" value:"true"`
+}
+
+// Sampling limits the entries of a logger with the same level and message.
+// Each Tick the first Initial of those entries are written, then every
+// Thereafter-th one, the others are dropped. Values left out or not positive
+// take the defaults of the value tags.
+type Sampling struct {
+	Initial    int           `json:"initial" yaml:"initial" value:"100"`
+	Thereafter int           `json:"thereafter" yaml:"thereafter" value:"100"`
+	Tick       time.Duration `json:"tick" yaml:"tick" value:"1s"`
+}
+
+// Dedup collapses consecutive entries of a logger with the same level and
+// template, or message for calls without a template. Repeats inside Window
+// are dropped and counted in a summary entry with a repeated field.
+type Dedup struct {
+	Window time.Duration `json:"window" yaml:"window" value:"1s"`
+}
+
+// Config is a logging config section. The type path globs of Level,
+// Stacktrace.Level, WithCallerType, Sampling and Dedup are compiled by
+// Validate, or on the first call of a GetXxxByType method, and the results are
+// cached per type path. Changes to these maps are seen after calling Validate
+// again or reloading the loggers built from the config.
 type Config struct {
 	Level       map[string]Level
 	Console     ConsoleLog
 	File        FileLog
-	WithCaller  bool `json:"withcaller" yaml:"withcaller" value:"true"`
-	WithLogName Name `json:"withlogname" yaml:"withlogname" value:"short"`
+	Sinks       []Sink     `json:"sinks" yaml:"sinks"`
+	Encoding    Encoding   `json:"encoding" yaml:"encoding"`
+	Stacktrace  Stacktrace `json:"stacktrace" yaml:"stacktrace"`
+	WithCaller  bool       `json:"withcaller" yaml:"withcaller" value:"true"`
+	WithLogName Name       `json:"withlogname" yaml:"withlogname" value:"short"`
+	// Development makes DPanic level logs panic after writing, like zap's
+	// development mode. In production DPanic only logs.
+	Development bool `json:"development" yaml:"development" value:"false"`
+	// WithCallerType maps type path globs to WithCaller overrides, matched like
+	// the Level of a Config.
+	WithCallerType map[string]bool `json:"withcallertype" yaml:"withcallertype"`
+	// Sampling maps type path globs to the sampling of the type, matched like
+	// the Level of a Config. Types without a match are not sampled.
+	Sampling map[string]*Sampling `json:"sampling" yaml:"sampling"`
+	// Dedup maps type path globs to the deduplication of the type, matched like
+	// the Level of a Config. Types without a match are not deduplicated.
+	Dedup map[string]*Dedup `json:"dedup" yaml:"dedup"`
+	// Priority ranks the type path globs used in Level and the other per type
+	// maps. Among the globs matching a type, one equal to the type path wins,
+	// then the highest priority, which defaults to 0, then the most literal
+	// characters.
+	Priority map[string]int `json:"priority" yaml:"priority"`
+
+	rules atomic.Pointer[configRules]
 }
 
 func (c *Config) Init() {
@@ -116,22 +385,186 @@ func (c *Config) Init() {
 	if _, ok := c.Level["*"]; !ok {
 		c.Level["*"] = LevelInfo
 	}
+
+	if c.Stacktrace.Level == nil {
+		c.Stacktrace.Level = make(map[string]Level)
+	}
+
+	if _, ok := c.Stacktrace.Level["*"]; !ok {
+		c.Stacktrace.Level["*"] = LevelError
+	}
+}
+
+// configRules holds the compiled type path globs of a Config.
+type configRules struct {
+	level      *typeRules[Level]
+	stacktrace *typeRules[Level]
+	withCaller *typeRules[bool]
+	sampling   *typeRules[*Sampling]
+	dedup      *typeRules[*Dedup]
+}
+
+// Validate compiles the type path globs of the config, returning an error for
+// an invalid one. The GetXxxByType methods use the compiled globs and cache
+// their results per type path; call Validate again after changing the maps of
+// a config in use. Loggers validate their config each time they are built.
+func (c *Config) Validate() error {
+	var err error
+	rules := &configRules{}
+
+	if rules.level, err = newTypeRules("level", c.Level, c.Priority); err != nil {
+		return err
+	}
+	if rules.stacktrace, err = newTypeRules("stacktrace.level", c.Stacktrace.Level, c.Priority); err != nil {
+		return err
+	}
+	if rules.withCaller, err = newTypeRules("withcallertype", c.WithCallerType, c.Priority); err != nil {
+		return err
+	}
+	if rules.sampling, err = newTypeRules("sampling", c.Sampling, c.Priority); err != nil {
+		return err
+	}
+	if rules.dedup, err = newTypeRules("dedup", c.Dedup, c.Priority); err != nil {
+		return err
+	}
+
+	c.rules.Store(rules)
+	return nil
+}
+
+// getRules returns the compiled globs, compiling them on first use.
+func (c *Config) getRules() *configRules {
+	if rules := c.rules.Load(); rules != nil {
+		return rules
+	}
+
+	if err := c.Validate(); err != nil {
+		panic(err)
+	}
+
+	return c.rules.Load()
 }
 
 func (c *Config) GetZapLevelByType(typePath string) Level {
-	maxPath := ""
-	maxPathLevel := LevelInfo
+	return c.getRules().level.matchOr(typePath, LevelInfo)
+}
+
+// LevelRule is a glob of the Level of a Config matching a type path.
+type LevelRule struct {
+	Pattern string `json:"pattern"`
+	Level   Level  `json:"level"`
+	// Exact reports a glob equal to the type path.
+	Exact    bool `json:"exact,omitempty"`
+	Priority int  `json:"priority,omitempty"`
+	// Specificity is the number of literal characters of the glob.
+	Specificity int `json:"specificity"`
+}
+
+// Explanation tells which rule of a config sets the level of a type path.
+type Explanation struct {
+	TypePath string `json:"typepath"`
+	// ConfigPath is the config section, empty for a Config value.
+	ConfigPath string `json:"configpath,omitempty"`
+	// Level is the configured level of the type. A level set at runtime is
+	// not taken into account.
+	Level Level `json:"level"`
+	// Rules are the matching globs, the winning one first. Without rules the
+	// type gets the info level.
+	Rules []LevelRule `json:"rules"`
+}
+
+// Explain returns the globs of Level matching the type path, in precedence
+// order, with the resulting level.
+func (c *Config) Explain(typePath string) Explanation {
+	explanation := Explanation{
+		TypePath: typePath,
+		Level:    c.GetZapLevelByType(typePath),
+		Rules:    []LevelRule{},
+	}
+
+	for _, rule := range c.getRules().level.matches(typePath) {
+		explanation.Rules = append(explanation.Rules, LevelRule{
+			Pattern:     rule.pattern,
+			Level:       rule.value,
+			Exact:       rule.pattern == typePath,
+			Priority:    rule.priority,
+			Specificity: rule.specificity,
+		})
+	}
+
+	return explanation
+}
+
+// GetWithCallerByType reports if the entries of the type carry the caller.
+func (c *Config) GetWithCallerByType(typePath string) bool {
+	return c.getRules().withCaller.matchOr(typePath, c.WithCaller)
+}
+
+// GetSamplingByType returns the sampling of the type, nil when it is not
+// sampled.
+func (c *Config) GetSamplingByType(typePath string) *Sampling {
+	return c.getRules().sampling.matchOr(typePath, nil)
+}
+
+// GetDedupWindowByType returns the dedup window of the type, 0 when it is not
+// deduplicated.
+func (c *Config) GetDedupWindowByType(typePath string) time.Duration {
+	dedup := c.getRules().dedup.matchOr(typePath, nil)
+	if dedup == nil {
+		return 0
+	}
+
+	if dedup.Window <= 0 {
+		return time.Second
+	}
+
+	return dedup.Window
+}
+
+// GetStacktraceLevelByType returns the minimum level capturing a stack for
+// the type, LevelInvalid when stacks are off.
+func (c *Config) GetStacktraceLevelByType(typePath string) Level {
+	return c.getRules().stacktrace.matchOr(typePath, LevelInvalid)
+}
+
+// GetSinks returns the console and file shorthands, when enabled, followed by
+// the sinks listed under `sinks`.
+func (c *Config) GetSinks() []Sink {
+	sinks := make([]Sink, 0, len(c.Sinks)+2)
+
+	if c.Console.Stream != ConsoleNo {
+		level := c.Console.Level
+		sinks = append(sinks, Sink{
+			Name:     SinkTypeConsole.String(),
+			Type:     SinkTypeConsole,
+			Encoder:  c.Console.Encoder,
+			Level:    &level,
+			Encoding: c.Console.Encoding,
+			Async:    c.Console.Async,
+			Stream:   c.Console.Stream,
+		})
+	}
 
-	for k, v := range c.Level {
-		if glob.MustCompile(k).Match(typePath) {
-			if len(maxPath) < len(k) {
-				maxPath = k
-				maxPathLevel = v
-			}
-		}
+	if len(c.File.Filename) > 0 {
+		level := c.File.Level
+		sinks = append(sinks, Sink{
+			Name:       SinkTypeFile.String(),
+			Type:       SinkTypeFile,
+			Encoder:    c.File.Encoder,
+			Level:      &level,
+			Encoding:   c.File.Encoding,
+			Async:      c.File.Async,
+			Filename:   c.File.Filename,
+			MaxSize:    c.File.MaxSize,
+			MaxAge:     c.File.MaxAge,
+			MaxBackups: c.File.MaxBackups,
+			LocalTime:  c.File.LocalTime,
+			Compress:   c.File.Compress,
+			Rotation:   c.File.Rotation,
+		})
 	}
 
-	return maxPathLevel
+	return append(sinks, c.Sinks...)
 }
 
 func (c *Config) GetName(typePath string) string {
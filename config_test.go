@@ -3,6 +3,7 @@ package log
 import (
 	"github.com/expgo/factory"
 	"github.com/expgo/structure"
+	"github.com/stretchr/testify/assert"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"testing"
 )
@@ -15,3 +16,9 @@ func TestStructChange(t *testing.T) {
 	}
 	log.Close()
 }
+
+func TestSinkLevelDefault(t *testing.T) {
+	c := factory.New[Config]()
+	assert.Equal(t, LevelDebug, c.Console.Level)
+	assert.Equal(t, LevelDebug, c.File.Level)
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"context"
+
+	"github.com/expgo/sync"
+)
+
+// ContextExtractor pulls key-value pairs out of a context. The pairs are
+// treated as they are in Logw and are added to every entry logged with one of
+// the Ctx methods.
+type ContextExtractor func(ctx context.Context) []any
+
+type loggerKey struct{}
+
+type contextExtractor struct {
+	f ContextExtractor
+}
+
+var contextExtractors []*contextExtractor
+var contextExtractorsLock = sync.NewRWMutex()
+
+// AddContextExtractor registers an extractor used by all loggers. Extractors
+// run in registration order, only for entries that are going to be written.
+// Call the returned function to unregister it.
+func AddContextExtractor(extractor ContextExtractor) (remove func()) {
+	ce := &contextExtractor{f: extractor}
+
+	contextExtractorsLock.Lock()
+	defer contextExtractorsLock.Unlock()
+
+	extractors := make([]*contextExtractor, 0, len(contextExtractors)+1)
+	extractors = append(extractors, contextExtractors...)
+	contextExtractors = append(extractors, ce)
+
+	return func() {
+		removeContextExtractor(ce)
+	}
+}
+
+func removeContextExtractor(ce *contextExtractor) {
+	contextExtractorsLock.Lock()
+	defer contextExtractorsLock.Unlock()
+
+	extractors := make([]*contextExtractor, 0, len(contextExtractors))
+	for _, e := range contextExtractors {
+		if e != ce {
+			extractors = append(extractors, e)
+		}
+	}
+	contextExtractors = extractors
+}
+
+// WithContext returns a copy of ctx carrying the logger.
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored by WithContext, or nil if ctx has none.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return l
+	}
+
+	return nil
+}
+
+// contextKeysAndValues runs the registered extractors against ctx.
+func contextKeysAndValues(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+
+	contextExtractorsLock.RLock()
+	extractors := contextExtractors
+	contextExtractorsLock.RUnlock()
+
+	var keysAndValues []any
+	for _, extractor := range extractors {
+		keysAndValues = append(keysAndValues, extractor.f(ctx)...)
+	}
+
+	return keysAndValues
+}
@@ -0,0 +1,187 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// dedup collapses consecutive entries of a logger with the same level and
+// template, or message when there is no template. The first entry is written,
+// the repeats inside the window only counted, and a summary entry carrying the
+// count is written when the window ends or another entry comes in.
+//
+// Every logger, including the ones derived with With and Named, keeps its own
+// state, so the summary carries the fields and name of the logger that was
+// repeated. The window is read from the root logger, which also tracks the
+// derived loggers holding a summary so that Close writes them.
+type dedup struct {
+	window atomic.Int64 // time.Duration, only set on the root logger
+	lock   sync.Mutex
+	level  zapcore.Level
+	key    string
+	msg    string
+	since  time.Time
+	count  int
+	timer  *time.Timer
+
+	// derived loggers with repeats counted, only set on the root logger
+	pendingLock sync.Mutex
+	pending     map[*logger]struct{}
+}
+
+// dedupSummary is the summary entry taken out of the dedup state, written
+// once the dedup lock is released.
+type dedupSummary struct {
+	level zapcore.Level
+	msg   string
+	count int
+}
+
+var noStacktrace = zap.LevelEnablerFunc(func(zapcore.Level) bool { return false })
+
+func (l *logger) setDedupWindow(window time.Duration) {
+	l.dedup.window.Store(int64(window))
+}
+
+func (l *logger) dedupWindow() time.Duration {
+	if l.root != nil {
+		return l.root.dedupWindow()
+	}
+
+	return time.Duration(l.dedup.window.Load())
+}
+
+// dedupMessage returns the message of the entry, or false when the entry
+// repeats the last one and is dropped. Panic and fatal levels always pass.
+// The message is formatted outside the dedup lock, so a value logging through
+// the same logger while it is formatted does not deadlock.
+func (l *logger) dedupMessage(lvl zapcore.Level, template string, message func() string) (string, bool) {
+	window := l.dedupWindow()
+	if window <= 0 || lvl >= zap.DPanicLevel {
+		return message(), true
+	}
+
+	msg := message()
+	key := template
+	if len(key) == 0 {
+		key = msg
+	}
+
+	d := &l.dedup
+	d.lock.Lock()
+
+	now := time.Now()
+	if len(d.key) > 0 && d.level == lvl && d.key == key && now.Sub(d.since) < window {
+		if d.count == 0 && l.root != nil {
+			l.root.trackDedup(l)
+		}
+		d.count++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.since.Add(window).Sub(now), l.expireDedup)
+		}
+		d.lock.Unlock()
+		return "", false
+	}
+
+	summary := l.takeDedupSummary()
+	d.level, d.key, d.msg, d.since = lvl, key, msg, now
+	d.lock.Unlock()
+
+	l.writeDedupSummary(summary)
+	return msg, true
+}
+
+func (l *logger) expireDedup() {
+	d := &l.dedup
+	d.lock.Lock()
+
+	// a new window started while the timer fired
+	if time.Since(d.since) < l.dedupWindow() {
+		d.lock.Unlock()
+		return
+	}
+
+	summary := l.takeDedupSummary()
+	d.key = ""
+	d.lock.Unlock()
+
+	l.writeDedupSummary(summary)
+}
+
+// flushDedup writes the summary of the repeats counted in the current window,
+// then the summaries of the derived loggers of a root logger.
+func (l *logger) flushDedup() {
+	l.dedup.lock.Lock()
+	summary := l.takeDedupSummary()
+	l.dedup.lock.Unlock()
+
+	l.writeDedupSummary(summary)
+
+	l.dedup.pendingLock.Lock()
+	pending := make([]*logger, 0, len(l.dedup.pending))
+	for child := range l.dedup.pending {
+		pending = append(pending, child)
+	}
+	l.dedup.pendingLock.Unlock()
+
+	for _, child := range pending {
+		child.flushDedup()
+	}
+}
+
+// trackDedup registers a derived logger holding a summary on its root.
+func (l *logger) trackDedup(child *logger) {
+	l.dedup.pendingLock.Lock()
+	defer l.dedup.pendingLock.Unlock()
+
+	if l.dedup.pending == nil {
+		l.dedup.pending = map[*logger]struct{}{}
+	}
+	l.dedup.pending[child] = struct{}{}
+}
+
+func (l *logger) untrackDedup(child *logger) {
+	l.dedup.pendingLock.Lock()
+	defer l.dedup.pendingLock.Unlock()
+
+	delete(l.dedup.pending, child)
+}
+
+// takeDedupSummary takes the summary of the logger, a derived logger is no
+// longer tracked by its root afterwards. The caller holds the dedup lock.
+func (l *logger) takeDedupSummary() dedupSummary {
+	summary := l.dedup.takeSummary()
+	if summary.count > 0 && l.root != nil {
+		l.root.untrackDedup(l)
+	}
+
+	return summary
+}
+
+// takeSummary resets the count of the current window and returns its summary.
+// The caller holds the dedup lock.
+func (d *dedup) takeSummary() dedupSummary {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	summary := dedupSummary{level: d.level, msg: d.msg, count: d.count}
+	d.count = 0
+	return summary
+}
+
+func (l *logger) writeDedupSummary(summary dedupSummary) {
+	if summary.count == 0 {
+		return
+	}
+
+	base := l.getBase().WithOptions(zap.WithCaller(false), zap.AddStacktrace(noStacktrace))
+	if ce := base.Check(summary.level, summary.msg); ce != nil {
+		ce.Write(zap.Int("repeated", summary.count))
+	}
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	gosync "sync"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	cfg.Dedup = map[string]*Dedup{"*Struct": {Window: 100 * time.Millisecond}}
+
+	structLog := LogWithConfig[MyLogStruct](cfg)
+	log := LogWithConfig[MyLog](cfg)
+
+	lock := gosync.Mutex{}
+	msgs := []string{}
+	defer AddGlobalHook(func(entry Entry) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		msg := fmt.Sprintf("%s %s", entry.Level, entry.Message)
+		if n, ok := entry.Fields["repeated"]; ok {
+			msg += fmt.Sprintf(" x%d", n)
+		}
+		if k, ok := entry.Fields["k"]; ok {
+			msg += fmt.Sprintf(" k=%v", k)
+		}
+		msgs = append(msgs, msg)
+	})()
+
+	for i := 0; i < 3; i++ {
+		structLog.Info("a")
+		log.Info("a")
+	}
+	structLog.Infof("b %d", 1)
+	structLog.Infof("b %d", 2)
+	structLog.Infow("c", "k", 1)
+	child := structLog.With("k", 2)
+	child.Infow("c")
+	child.Infow("c")
+	structLog.Warn("c")
+	structLog.Warn("c")
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(msgs) == 12
+	}, time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Equal(t, []string{"Info a", "Info a", "Info a", "Info a"}, msgs[:4])
+	assert.Equal(t, []string{
+		"Info a x2", "Info b 1",
+		"Info b 1 x1", "Info c k=1",
+		"Info c k=2", "Warn c",
+	}, msgs[4:10])
+	// the summaries of the two loggers expire independently
+	assert.ElementsMatch(t, []string{"Info c x1 k=2", "Warn c x1"}, msgs[10:])
+}
+
+type loggingStringer struct {
+	log Logger
+}
+
+func (s loggingStringer) String() string {
+	s.log.Info("inner")
+	return "outer"
+}
+
+func TestDedupReentrant(t *testing.T) {
+	cfg, filename := newTestConfig(t)
+	cfg.Dedup = map[string]*Dedup{"*": {Window: time.Hour}}
+
+	log := LogWithConfig[MyLogStruct](cfg)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		log.Infof("%s", loggingStringer{log})
+		log.Infof("%s", loggingStringer{log})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging from a Stringer deadlocked")
+	}
+
+	out := readLog(t, log, filename)
+	assert.Contains(t, out, "inner")
+	assert.Contains(t, out, "outer")
+}
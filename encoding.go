@@ -0,0 +1,208 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// omitKey is the key value leaving a field out of the entries.
+const omitKey = "-"
+
+// defaultEncoding matches the value tags of Encoding. Configs not created by
+// the factory fall back to it for empty values.
+var defaultEncoding = Encoding{
+	TimeKey:       "time",
+	LevelKey:      "level",
+	NameKey:       "logger",
+	CallerKey:     "caller",
+	MessageKey:    "msg",
+	StacktraceKey: "stack",
+	TimeFormat:    "2006-01-02T15:04:05.000000",
+	TimeZone:      "Local",
+	LevelCase:     LevelCaseLower,
+	Duration:      DurationFormatString,
+	Caller:        CallerFormatDefault,
+}
+
+// merge returns a copy of e with the non-empty values of override applied.
+func (e *Encoding) merge(override *Encoding) *Encoding {
+	merged := *e
+	if override == nil {
+		return &merged
+	}
+
+	set := func(to *string, from string) {
+		if len(from) > 0 {
+			*to = from
+		}
+	}
+
+	set(&merged.TimeKey, override.TimeKey)
+	set(&merged.LevelKey, override.LevelKey)
+	set(&merged.NameKey, override.NameKey)
+	set(&merged.CallerKey, override.CallerKey)
+	set(&merged.MessageKey, override.MessageKey)
+	set(&merged.StacktraceKey, override.StacktraceKey)
+	set(&merged.TimeFormat, override.TimeFormat)
+	set(&merged.TimeZone, override.TimeZone)
+
+	if len(override.LevelCase) > 0 {
+		merged.LevelCase = override.LevelCase
+	}
+	if len(override.Duration) > 0 {
+		merged.Duration = override.Duration
+	}
+	if len(override.Caller) > 0 {
+		merged.Caller = override.Caller
+	}
+
+	return &merged
+}
+
+// encoderConfig builds the zap encoder config. Colored levels are used for
+// text consoles only.
+func (e *Encoding) encoderConfig(color bool) (zapcore.EncoderConfig, error) {
+	key := func(k string) string {
+		if k == omitKey {
+			return zapcore.OmitKey
+		}
+		return k
+	}
+
+	encodeTime, err := e.timeEncoder()
+	if err != nil {
+		return zapcore.EncoderConfig{}, err
+	}
+
+	callerKey := key(e.CallerKey)
+	if e.Caller == CallerFormatFields {
+		// written as fields by the callerFieldsCore of the sink
+		callerKey = zapcore.OmitKey
+	}
+
+	return zapcore.EncoderConfig{
+		TimeKey:        key(e.TimeKey),
+		LevelKey:       key(e.LevelKey),
+		NameKey:        key(e.NameKey),
+		CallerKey:      callerKey,
+		FunctionKey:    zapcore.OmitKey,
+		MessageKey:     key(e.MessageKey),
+		StacktraceKey:  key(e.StacktraceKey),
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    e.levelEncoder(color),
+		EncodeTime:     encodeTime,
+		EncodeDuration: e.durationEncoder(),
+		EncodeCaller:   e.callerEncoder(),
+	}, nil
+}
+
+func (e *Encoding) callerEncoder() zapcore.CallerEncoder {
+	switch e.Caller {
+	case CallerFormatShort:
+		return zapcore.ShortCallerEncoder
+	case CallerFormatFull:
+		return zapcore.FullCallerEncoder
+	case CallerFormatFunction:
+		return functionCallerEncoder
+	default:
+		return fullCallerEncoder
+	}
+}
+
+func functionCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(caller.Function)
+}
+
+// callerFieldsCore writes the caller of the entries as file, line and function
+// fields, for the fields caller format.
+type callerFieldsCore struct {
+	zapcore.Core
+}
+
+func newCallerFieldsCore(core zapcore.Core) zapcore.Core {
+	return &callerFieldsCore{Core: core}
+}
+
+func (c *callerFieldsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &callerFieldsCore{Core: c.Core.With(fields)}
+}
+
+func (c *callerFieldsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *callerFieldsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Caller.Defined {
+		fields = append(fields[:len(fields):len(fields)],
+			zap.String("file", ent.Caller.File),
+			zap.Int("line", ent.Caller.Line),
+			zap.String("function", ent.Caller.Function),
+		)
+	}
+
+	return c.Core.Write(ent, fields)
+}
+
+func (e *Encoding) levelEncoder(color bool) zapcore.LevelEncoder {
+	upper := e.LevelCase == LevelCaseUpper
+
+	switch {
+	case color && upper:
+		return zapcore.CapitalColorLevelEncoder
+	case color:
+		return zapcore.LowercaseColorLevelEncoder
+	case upper:
+		return zapcore.CapitalLevelEncoder
+	default:
+		return zapcore.LowercaseLevelEncoder
+	}
+}
+
+func (e *Encoding) durationEncoder() zapcore.DurationEncoder {
+	switch e.Duration {
+	case DurationFormatNanos:
+		return zapcore.NanosDurationEncoder
+	case DurationFormatMillis:
+		return zapcore.MillisDurationEncoder
+	case DurationFormatSeconds:
+		return zapcore.SecondsDurationEncoder
+	default:
+		return zapcore.StringDurationEncoder
+	}
+}
+
+func (e *Encoding) timeEncoder() (zapcore.TimeEncoder, error) {
+	loc, err := time.LoadLocation(e.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone '%s': %w", e.TimeZone, err)
+	}
+
+	var encode zapcore.TimeEncoder
+	switch strings.ToLower(e.TimeFormat) {
+	case "rfc3339":
+		encode = zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		encode = zapcore.RFC3339NanoTimeEncoder
+	case "iso8601":
+		encode = zapcore.ISO8601TimeEncoder
+	case "epoch":
+		encode = zapcore.EpochTimeEncoder
+	case "epochmillis":
+		encode = zapcore.EpochMillisTimeEncoder
+	case "epochnanos":
+		encode = zapcore.EpochNanosTimeEncoder
+	default:
+		encode = zapcore.TimeEncoderOfLayout(e.TimeFormat)
+	}
+
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		encode(t.In(loc), enc)
+	}, nil
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEncoding(t *testing.T) {
+	cfg, filename := newTestConfig(t)
+	cfg.Encoding.TimeKey = "@timestamp"
+	cfg.Encoding.TimeFormat = "rfc3339nano"
+	cfg.Encoding.TimeZone = "UTC"
+	cfg.Encoding.LevelKey = "severity"
+	cfg.Encoding.LevelCase = LevelCaseUpper
+	cfg.Encoding.Duration = DurationFormatMillis
+	cfg.File.Encoder = EncoderJson
+	cfg.File.Encoding = &Encoding{MessageKey: "message", CallerKey: "-"}
+
+	log := LogWithConfig[MyLogStruct](cfg)
+	log.Infow("hello", "elapsed", 1500*time.Millisecond)
+
+	out := readLog(t, log, filename)
+	assert.Regexp(t, `^\{"severity":"INFO","@timestamp":"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z","logger":"log.MyLogStruct","message":"hello","elapsed":1500\}`, out)
+
+	cfg.Encoding.TimeZone = "Nowhere/Invalid"
+	assert.Error(t, log.Reload())
+}
+
+func TestCaller(t *testing.T) {
+	cfg, filename := newTestConfig(t)
+	dir := filepath.Dir(filename)
+	cfg.Encoding.Caller = CallerFormatFields
+	cfg.File.Encoder = EncoderJson
+	cfg.Sinks = []Sink{
+		{Type: SinkTypeFile, Filename: filepath.Join(dir, "short.log"), Encoding: &Encoding{Caller: CallerFormatShort}},
+		{Type: SinkTypeFile, Filename: filepath.Join(dir, "function.log"), Encoding: &Encoding{Caller: CallerFormatFunction}},
+	}
+	cfg.WithCallerType = map[string]bool{"*MyLog": false}
+
+	structLog := LogWithConfig[MyLogStruct](cfg)
+	log := LogWithConfig[MyLog](cfg)
+	structLog.Info("hello")
+	log.Info("no caller")
+
+	fields := readLog(t, structLog, filename)
+	assert.Regexp(t, `"msg":"hello","file":"[^"]+/encoding_test.go","line":\d+,"function":"github.com/expgo/log.TestCaller"}`, fields)
+	assert.Regexp(t, `"msg":"no caller"}`, fields)
+	assert.NotContains(t, fields, `"caller"`)
+
+	assert.Regexp(t, `\t[^\t/]+/encoding_test.go:\d+\thello`, readLog(t, structLog, filepath.Join(dir, "short.log")))
+	assert.Contains(t, readLog(t, structLog, filepath.Join(dir, "function.log")), "\tgithub.com/expgo/log.TestCaller\thello")
+}
@@ -0,0 +1,105 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// envPrefix returns the prefix of the environment variables overriding a
+// config section: LOG for the default logging section, otherwise the upper
+// cased section name, like LOG1 for log1.
+func envPrefix(cfgPath string) string {
+	if cfgPath == DefaultConfigPath {
+		return "LOG"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(cfgPath))
+}
+
+// envSetter applies the value of one environment variable to a config.
+type envSetter struct {
+	name string
+	set  func(c *Config, value string) error
+}
+
+// envSetters lists the supported variables, without their prefix.
+var envSetters = []envSetter{
+	{"LEVEL", func(c *Config, v string) error { return parseEnvLevels(v, c.Level) }},
+	{"CONSOLE", envValue(ParseConsole, func(c *Config) *Console { return &c.Console.Stream })},
+	{"CONSOLE_ENCODER", envValue(ParseEncoder, func(c *Config) *Encoder { return &c.Console.Encoder })},
+	{"CONSOLE_LEVEL", envValue(ParseLevel, func(c *Config) *Level { return &c.Console.Level })},
+	{"FILE", func(c *Config, v string) error { c.File.Filename = v; return nil }},
+	{"FILE_ENCODER", envValue(ParseEncoder, func(c *Config) *Encoder { return &c.File.Encoder })},
+	{"FILE_LEVEL", envValue(ParseLevel, func(c *Config) *Level { return &c.File.Level })},
+	{"FILE_MAXSIZE", envValue(strconv.Atoi, func(c *Config) *int { return &c.File.MaxSize })},
+	{"FILE_MAXAGE", envValue(strconv.Atoi, func(c *Config) *int { return &c.File.MaxAge })},
+	{"FILE_MAXBACKUPS", envValue(strconv.Atoi, func(c *Config) *int { return &c.File.MaxBackups })},
+	{"FILE_COMPRESS", envValue(strconv.ParseBool, func(c *Config) *bool { return &c.File.Compress })},
+	{"FILE_ROTATION", envValue(ParseRotation, func(c *Config) *Rotation { return &c.File.Rotation })},
+	{"STACKTRACE_LEVEL", func(c *Config, v string) error { return parseEnvLevels(v, c.Stacktrace.Level) }},
+	{"WITHCALLER", envValue(strconv.ParseBool, func(c *Config) *bool { return &c.WithCaller })},
+	{"WITHLOGNAME", envValue(ParseName, func(c *Config) *Name { return &c.WithLogName })},
+	{"DEVELOPMENT", envValue(strconv.ParseBool, func(c *Config) *bool { return &c.Development })},
+}
+
+func envValue[T any](parse func(string) (T, error), field func(c *Config) *T) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		v, err := parse(value)
+		if err != nil {
+			return err
+		}
+
+		*field(c) = v
+		return nil
+	}
+}
+
+// parseEnvLevels merges a list like "*=info,*MyLog=debug" into levels. A
+// single level without a glob applies to "*".
+func parseEnvLevels(value string, levels map[string]Level) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+
+		pattern, name, ok := strings.Cut(item, "=")
+		if !ok {
+			pattern, name = "*", item
+		}
+
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		levels[strings.TrimSpace(pattern)] = level
+	}
+
+	return nil
+}
+
+// applyEnv overlays the environment variables with the prefix on the config,
+// so they take precedence over the config section and the defaults.
+func (c *Config) applyEnv(prefix string) error {
+	for _, setter := range envSetters {
+		name := prefix + "_" + setter.name
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := setter.set(c, value); err != nil {
+			return fmt.Errorf("invalid %s '%s': %w", name, value, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"github.com/expgo/config"
+	"github.com/expgo/factory"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	assert.Equal(t, "LOG", envPrefix(DefaultConfigPath))
+	assert.Equal(t, "LOG1", envPrefix("log1"))
+	assert.Equal(t, "APP_LOG", envPrefix("app.log"))
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("LOG1_LEVEL", "warn,*MyLog=debug")
+	t.Setenv("LOG1_CONSOLE", "stderr")
+	t.Setenv("LOG1_CONSOLE_ENCODER", "json")
+	t.Setenv("LOG1_FILE", "log/env.log")
+	t.Setenv("LOG1_FILE_MAXSIZE", "10")
+	t.Setenv("LOG1_FILE_COMPRESS", "false")
+	t.Setenv("LOG1_WITHCALLER", "false")
+	t.Setenv("LOG_LEVEL", "error")
+
+	cfg := factory.New[Config]()
+	cfg.Level["*MyLogStruct"] = LevelInfo
+	assert.NoError(t, cfg.applyEnv("LOG1"))
+
+	assert.Equal(t, map[string]Level{"*": LevelWarn, "*MyLog": LevelDebug, "*MyLogStruct": LevelInfo}, cfg.Level)
+	assert.Equal(t, ConsoleStderr, cfg.Console.Stream)
+	assert.Equal(t, EncoderJson, cfg.Console.Encoder)
+	assert.Equal(t, "log/env.log", cfg.File.Filename)
+	assert.Equal(t, 10, cfg.File.MaxSize)
+	assert.False(t, cfg.File.Compress)
+	assert.False(t, cfg.WithCaller)
+
+	t.Setenv("LOG1_FILE_ENCODER", "xml")
+	assert.EqualError(t, cfg.applyEnv("LOG1"), "invalid LOG1_FILE_ENCODER 'xml': xml is not a valid Encoder")
+}
+
+func TestEnvOverridesSection(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	filename := filepath.Join(t.TempDir(), "env.log")
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"level":   map[string]any{"*": "info"},
+		"console": map[string]any{"stream": "stdout"},
+	}, "envlog"))
+	t.Setenv("ENVLOG_LEVEL", "*MyLogStruct=debug")
+	t.Setenv("ENVLOG_CONSOLE", "no")
+	t.Setenv("ENVLOG_FILE", filename)
+
+	structLog := LogWithConfigPath[MyLogStruct]("envlog")
+	log := LogWithConfigPath[MyLog]("envlog")
+	assert.Equal(t, LevelDebug, structLog.Level())
+	assert.Equal(t, LevelInfo, log.Level())
+
+	sinks := List()[1].Sinks
+	assert.Len(t, sinks, 1)
+	assert.Equal(t, filename, sinks[0].Filename)
+
+	t.Setenv("ENVLOG_LEVEL", "*MyLogStruct=error")
+	assert.NoError(t, Reload())
+	assert.Equal(t, LevelError, structLog.Level())
+}
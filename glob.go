@@ -0,0 +1,169 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gobwas/glob"
+)
+
+// compileGlob compiles the pattern. The globs of a config are held by its
+// typeRules, other patterns are compiled for each use.
+func compileGlob(pattern string) (glob.Glob, error) {
+	g, err := glob.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob '%s': %w", pattern, err)
+	}
+
+	return g, nil
+}
+
+type typeRule[V any] struct {
+	pattern     string
+	glob        glob.Glob
+	value       V
+	priority    int
+	specificity int
+}
+
+type typeMatch[V any] struct {
+	value V
+	ok    bool
+}
+
+// typeRules resolves values from a map of type path globs, like the Level of
+// a Config. The globs are compiled once and the result is cached per type path.
+//
+// Among the globs matching a type path, a glob equal to the type path wins,
+// then the one with the highest priority, then the one with the most literal
+// characters. Remaining ties go to the longer glob, then the first in byte
+// order, so the result never depends on map order.
+type typeRules[V any] struct {
+	rules []typeRule[V]
+	exact map[string]int // index in rules of the globs without wildcards
+	cache sync.Map
+}
+
+func newTypeRules[V any](name string, values map[string]V, priorities map[string]int) (*typeRules[V], error) {
+	r := &typeRules[V]{
+		rules: make([]typeRule[V], 0, len(values)),
+		exact: map[string]int{},
+	}
+
+	for pattern, value := range values {
+		g, err := compileGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		r.rules = append(r.rules, typeRule[V]{
+			pattern:     pattern,
+			glob:        g,
+			value:       value,
+			priority:    priorities[pattern],
+			specificity: literalCount(pattern),
+		})
+	}
+
+	sort.Slice(r.rules, func(i, j int) bool {
+		a, b := r.rules[i], r.rules[j]
+		switch {
+		case a.priority != b.priority:
+			return a.priority > b.priority
+		case a.specificity != b.specificity:
+			return a.specificity > b.specificity
+		case len(a.pattern) != len(b.pattern):
+			return len(a.pattern) > len(b.pattern)
+		default:
+			return a.pattern < b.pattern
+		}
+	})
+
+	for i, rule := range r.rules {
+		if rule.specificity == len(rule.pattern) {
+			r.exact[rule.pattern] = i
+		}
+	}
+
+	return r, nil
+}
+
+// literalCount returns the number of characters of the pattern matching only
+// themselves, outside wildcards, character classes and alternatives.
+func literalCount(pattern string) int {
+	count, depth := 0, 0
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+			if depth == 0 {
+				count++
+			}
+		case '[', '{':
+			depth++
+		case ']', '}':
+			if depth > 0 {
+				depth--
+			}
+		case '*', '?':
+		default:
+			if depth == 0 {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// matches returns the rules matching typePath, the winning one first.
+func (r *typeRules[V]) matches(typePath string) []typeRule[V] {
+	matches := []typeRule[V]{}
+
+	exact, ok := r.exact[typePath]
+	if ok {
+		matches = append(matches, r.rules[exact])
+	}
+
+	for i, rule := range r.rules {
+		if (!ok || i != exact) && rule.glob.Match(typePath) {
+			matches = append(matches, rule)
+		}
+	}
+
+	return matches
+}
+
+// match returns the value of the winning rule for typePath, false when no
+// glob matches.
+func (r *typeRules[V]) match(typePath string) (V, bool) {
+	if m, ok := r.cache.Load(typePath); ok {
+		return m.(typeMatch[V]).value, m.(typeMatch[V]).ok
+	}
+
+	m := typeMatch[V]{}
+	if exact, ok := r.exact[typePath]; ok {
+		m = typeMatch[V]{value: r.rules[exact].value, ok: true}
+	} else {
+		for _, rule := range r.rules {
+			if rule.glob.Match(typePath) {
+				m = typeMatch[V]{value: rule.value, ok: true}
+				break
+			}
+		}
+	}
+
+	r.cache.Store(typePath, m)
+	return m.value, m.ok
+}
+
+// matchOr returns the matching value, or defaultValue when no glob matches.
+func (r *typeRules[V]) matchOr(typePath string, defaultValue V) V {
+	if v, ok := r.match(typePath); ok {
+		return v
+	}
+
+	return defaultValue
+}
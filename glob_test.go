@@ -0,0 +1,136 @@
+package log
+
+import (
+	"github.com/expgo/config"
+	"github.com/expgo/factory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompileGlob(t *testing.T) {
+	g, err := compileGlob("*MyLog")
+	assert.NoError(t, err)
+	assert.True(t, g.Match("github.com/expgo/log.MyLog"))
+
+	_, err = compileGlob("[")
+	assert.ErrorContains(t, err, "invalid glob '['")
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := factory.New[Config]()
+	cfg.Level["*MyLog"] = LevelDebug
+	assert.NoError(t, cfg.Validate())
+
+	assert.Equal(t, LevelDebug, cfg.GetZapLevelByType("github.com/expgo/log.MyLog"))
+	assert.Equal(t, LevelInfo, cfg.GetZapLevelByType("github.com/expgo/log.MyLogStruct"))
+	_, ok := cfg.rules.Load().level.cache.Load("github.com/expgo/log.MyLog")
+	assert.True(t, ok)
+
+	// the cached rules are used until the next Validate
+	cfg.Level["*MyLog"] = LevelError
+	assert.Equal(t, LevelDebug, cfg.GetZapLevelByType("github.com/expgo/log.MyLog"))
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, LevelError, cfg.GetZapLevelByType("github.com/expgo/log.MyLog"))
+
+	cfg.Stacktrace.Level["[a"] = LevelWarn
+	assert.ErrorContains(t, cfg.Validate(), "stacktrace.level: invalid glob '[a'")
+}
+
+func TestInvalidGlob(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	assert.NoError(t, config.SetConfig(map[string]any{"level": map[string]any{"*": "info"}}, "glob"))
+	log := LogWithConfigPath[MyLogStruct]("glob")
+	log.Info("hello")
+
+	assert.NoError(t, config.SetConfig(map[string]any{"level": map[string]any{"[": "debug"}}, "glob"))
+	assert.ErrorContains(t, log.Reload(), "config 'glob': level: invalid glob '['")
+	assert.Equal(t, LevelInfo, log.Level())
+
+	// a logger whose first build fails writes to stderr
+	stderr := redirectStderr(t)
+	cfg := factory.New[Config]()
+	cfg.Level["["] = LevelDebug
+	bad := LogWithConfig[MyLog](cfg)
+	assert.NotPanics(t, func() {
+		bad.Info("hello")
+	})
+	assert.ErrorContains(t, bad.Reload(), "level: invalid glob '['")
+
+	out := readLog(t, bad, stderr)
+	assert.Contains(t, out, "level: invalid glob '['")
+	assert.Contains(t, out, "\thello\n")
+
+	delete(cfg.Level, "[")
+	assert.NoError(t, bad.Reload())
+}
+
+func TestLiteralCount(t *testing.T) {
+	assert.Equal(t, 0, literalCount("*"))
+	assert.Equal(t, 5, literalCount("*MyLog"))
+	assert.Equal(t, 16, literalCount("github.com/acme/*"))
+	assert.Equal(t, 3, literalCount("a?[bc]{d,e}f\\*"))
+}
+
+func TestLevelPrecedence(t *testing.T) {
+	typePath := "github.com/acme/pkg.MyLog"
+
+	cfg := factory.New[Config]()
+	cfg.Level["github.com/acme/*"] = LevelWarn
+	cfg.Level["*MyLog"] = LevelDebug
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, LevelWarn, cfg.GetZapLevelByType(typePath))
+
+	cfg.Priority = map[string]int{"*MyLog": 1}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, LevelDebug, cfg.GetZapLevelByType(typePath))
+
+	cfg.Level[typePath] = LevelError
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, LevelError, cfg.GetZapLevelByType(typePath))
+
+	explanation := cfg.Explain(typePath)
+	assert.Equal(t, LevelError, explanation.Level)
+	assert.Equal(t, []LevelRule{
+		{Pattern: typePath, Level: LevelError, Exact: true, Specificity: 25},
+		{Pattern: "*MyLog", Level: LevelDebug, Priority: 1, Specificity: 5},
+		{Pattern: "github.com/acme/*", Level: LevelWarn, Specificity: 16},
+		{Pattern: "*", Level: LevelInfo},
+	}, explanation.Rules)
+
+	// ties do not depend on map order
+	for i := 0; i < 20; i++ {
+		cfg := factory.New[Config]()
+		cfg.Level["*b*"] = LevelError
+		cfg.Level["*a*"] = LevelWarn
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, LevelWarn, cfg.GetZapLevelByType("ab"))
+	}
+}
+
+func TestExplain(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"level":    map[string]any{"*": "info", "*MyLog": "debug", "github.com/expgo/*": "warn"},
+		"priority": map[string]any{"*MyLog": 10},
+	}, "explain"))
+	cfg := factory.New[Config]()
+
+	LogWithConfigPath[MyLog]("explain")
+	LogWithConfig[MyLog](cfg)
+	LogWithConfigPath[MyLogStruct]("explain")
+
+	explanations := Explain("github.com/expgo/log.MyLog")
+	assert.Len(t, explanations, 2)
+
+	assert.Empty(t, explanations[0].ConfigPath)
+	assert.Equal(t, LevelInfo, explanations[0].Level)
+	assert.Len(t, explanations[0].Rules, 1)
+
+	assert.Equal(t, "explain", explanations[1].ConfigPath)
+	assert.Equal(t, LevelDebug, explanations[1].Level)
+	assert.Equal(t, []string{"*MyLog", "github.com/expgo/*", "*"}, []string{
+		explanations[1].Rules[0].Pattern, explanations[1].Rules[1].Pattern, explanations[1].Rules[2].Pattern,
+	})
+}
@@ -0,0 +1,160 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry is a written log entry as seen by an EntryHook.
+type Entry struct {
+	Level      Level
+	Time       time.Time
+	LoggerName string
+	// TypePath is the type path of the registered logger that wrote the entry.
+	TypePath string
+	Message  string
+	// Fields holds the fields bound with With and the key-value pairs of the
+	// call. Namespaces become nested maps.
+	Fields map[string]any
+	Caller zapcore.EntryCaller
+	Stack  string
+}
+
+// EntryHook is called for every entry written by a logger, after it passed the
+// level check.
+type EntryHook func(entry Entry)
+
+type entryHook struct {
+	f EntryHook
+}
+
+// hookList is a copy-on-write list of hooks. Writers take the lock, the hot
+// logging path only loads the current slice.
+type hookList struct {
+	lock  sync.Mutex
+	hooks atomic.Pointer[[]*entryHook]
+}
+
+var globalHooks hookList
+
+// AddGlobalHook adds a hook called for every entry written by any logger,
+// including loggers created after the call. The returned func removes it.
+func AddGlobalHook(f EntryHook) (remove func()) {
+	return globalHooks.add(f)
+}
+
+func (hl *hookList) add(f EntryHook) (remove func()) {
+	hook := &entryHook{f: f}
+
+	hl.lock.Lock()
+	defer hl.lock.Unlock()
+
+	old := hl.load()
+	hooks := make([]*entryHook, 0, len(old)+1)
+	hooks = append(hooks, old...)
+	hooks = append(hooks, hook)
+	hl.hooks.Store(&hooks)
+
+	return func() {
+		hl.remove(hook)
+	}
+}
+
+func (hl *hookList) remove(hook *entryHook) {
+	hl.lock.Lock()
+	defer hl.lock.Unlock()
+
+	old := hl.load()
+	hooks := make([]*entryHook, 0, len(old))
+	for _, h := range old {
+		if h != hook {
+			hooks = append(hooks, h)
+		}
+	}
+	hl.hooks.Store(&hooks)
+}
+
+func (hl *hookList) load() []*entryHook {
+	if hooks := hl.hooks.Load(); hooks != nil {
+		return *hooks
+	}
+
+	return nil
+}
+
+// hookCore runs the entry hooks of a logger. It keeps the fields added through
+// With, so hooks see the same fields as the sinks.
+type hookCore struct {
+	zapcore.Core
+	l      *logger
+	fields []zapcore.Field
+}
+
+func newHookCore(core zapcore.Core, l *logger) zapcore.Core {
+	return &hookCore{Core: core, l: l}
+}
+
+func (c *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	clone = append(clone, c.fields...)
+	clone = append(clone, fields...)
+
+	return &hookCore{
+		Core:   c.Core.With(fields),
+		l:      c.l,
+		fields: clone,
+	}
+}
+
+func (c *hookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	downstream := c.Core.Check(ent, ce)
+	if downstream == nil || (len(globalHooks.load()) == 0 && len(c.l.hooks.load()) == 0) {
+		return downstream
+	}
+
+	return downstream.AddCore(ent, c)
+}
+
+// Write only runs the hooks, the wrapped core was added to the checked entry
+// by Check and writes the entry itself.
+func (c *hookCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	global, local := globalHooks.load(), c.l.hooks.load()
+	if len(global) == 0 && len(local) == 0 {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	if cfg := c.l.active.Load(); cfg != nil && cfg.Stacktrace.Trim && len(ent.Stack) > 0 {
+		ent.Stack = trimStack(ent.Stack)
+	}
+
+	entry := Entry{
+		Level:      Level(ent.Level),
+		Time:       ent.Time,
+		LoggerName: ent.LoggerName,
+		TypePath:   c.l.typePath,
+		Message:    ent.Message,
+		Fields:     enc.Fields,
+		Caller:     ent.Caller,
+		Stack:      ent.Stack,
+	}
+
+	for _, hook := range global {
+		hook.f(entry)
+	}
+	for _, hook := range local {
+		hook.f(entry)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+)
+
+// levelRequest is the body of a level change sent to the Handler.
+type levelRequest struct {
+	Glob     string `json:"glob"`
+	Level    string `json:"level"`
+	Duration string `json:"duration"`
+}
+
+type handler struct{}
+
+// Handler returns an http.Handler for viewing and changing levels at runtime.
+//
+// GET lists the registered loggers as json, like List, optionally filtered by
+// a glob query parameter. PUT and POST set the level of the loggers matching
+// a glob, for a duration when one is given, like SetLevel and
+// TemporarySetLevel, then list those loggers. The glob, level and duration are
+// read from a json body, or from the query and form values:
+//
+//	curl -X PUT -H 'Content-Type: application/json' -d '{"glob":"*MyLog","level":"debug","duration":"10m"}' http://localhost:8080/log
+//	curl -X PUT 'http://localhost:8080/log?glob=*&level=info'
+func Handler() http.Handler {
+	return handler{}
+}
+
+func (handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		filter, err := compileFilter(r.URL.Query().Get("glob"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, filterInfos(filter))
+	case http.MethodPut, http.MethodPost:
+		req, err := readLevelRequest(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if len(req.Glob) == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("glob is required"))
+			return
+		}
+		filter, err := compileFilter(req.Glob)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		level, err := ParseLevel(req.Level)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if level == LevelInvalid {
+			// it would turn off every entry, fatal ones included
+			writeError(w, http.StatusBadRequest, fmt.Errorf("level '%s' can not be set", req.Level))
+			return
+		}
+
+		var d time.Duration
+		if len(req.Duration) > 0 {
+			if d, err = time.ParseDuration(req.Duration); err != nil || d <= 0 {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid duration '%s'", req.Duration))
+				return
+			}
+		}
+
+		temporarySetLevel(filter, level, d)
+		writeJSON(w, http.StatusOK, filterInfos(filter))
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func readLevelRequest(r *http.Request) (*levelRequest, error) {
+	req := &levelRequest{}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, fmt.Errorf("invalid json body: %w", err)
+		}
+		return req, nil
+	}
+
+	req.Glob = r.FormValue("glob")
+	req.Level = r.FormValue("level")
+	req.Duration = r.FormValue("duration")
+
+	return req, nil
+}
+
+func compileFilter(pattern string) (glob.Glob, error) {
+	if len(pattern) == 0 {
+		return nil, nil
+	}
+
+	return compileGlob(pattern)
+}
+
+func filterInfos(filter glob.Glob) []LoggerInfo {
+	infos := List()
+	if filter == nil {
+		return infos
+	}
+
+	filtered := make([]LoggerInfo, 0, len(infos))
+	for _, info := range infos {
+		if filter.Match(info.TypePath) {
+			filtered = append(filtered, info)
+		}
+	}
+
+	return filtered
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,96 @@
+package log
+
+import (
+	"encoding/json"
+	"github.com/expgo/factory"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	cfg := factory.New[Config]()
+	cfg.Console.Stream = ConsoleNo
+	structLog := LogWithConfig[MyLogStruct](cfg)
+	log := LogWithConfig[MyLog](cfg)
+	defer structLog.TemporarySetLevel(LevelInfo, 0)
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	do := func(method string, url string, contentType string, body string) (int, []LoggerInfo, map[string]string) {
+		req, err := http.NewRequest(method, server.URL+url, strings.NewReader(body))
+		assert.NoError(t, err)
+		if len(contentType) > 0 {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		if resp.StatusCode != http.StatusOK {
+			errBody := map[string]string{}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&errBody))
+			return resp.StatusCode, nil, errBody
+		}
+
+		infos := []LoggerInfo{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&infos))
+		return resp.StatusCode, infos, nil
+	}
+
+	status, infos, _ := do(http.MethodGet, "/", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, infos, 2)
+
+	status, infos, _ = do(http.MethodGet, "/?glob=*Struct", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, infos, 1)
+	assert.Equal(t, LevelInfo, infos[0].Level)
+
+	status, infos, _ = do(http.MethodPut, "/", "application/json", `{"glob":"*Struct","level":"debug","duration":"1h"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, infos, 1)
+	assert.Equal(t, LevelDebug, infos[0].Level)
+	assert.NotNil(t, infos[0].TemporaryUntil)
+	assert.Equal(t, LevelDebug, structLog.Level())
+	assert.Equal(t, LevelInfo, log.Level())
+
+	status, infos, _ = do(http.MethodPost, "/?glob=*MyLog&level=error", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, infos, 1)
+	assert.Nil(t, infos[0].TemporaryUntil)
+	assert.Equal(t, LevelError, log.Level())
+
+	status, _, errBody := do(http.MethodPut, "/", "application/json", `{"glob":"*","level":"loud"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, errBody["error"], "loud")
+
+	status, _, errBody = do(http.MethodPut, "/", "application/json", `{"glob":"*","level":"invalid"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, errBody["error"], "invalid")
+
+	status, _, errBody = do(http.MethodPut, "/", "application/json", `{"glob":"[","level":"info"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, errBody["error"], "invalid glob")
+
+	status, _, _ = do(http.MethodPut, "/", "application/json", `{"glob":"*","level":"info","duration":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _, _ = do(http.MethodPut, "/", "application/json", `{"level":"info"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _, _ = do(http.MethodDelete, "/", "", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+
+	assert.Equal(t, LevelDebug, structLog.Level())
+	assert.Equal(t, LevelError, log.Level())
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *List()[1].TemporaryUntil, time.Minute)
+}
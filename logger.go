@@ -1,16 +1,17 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"github.com/expgo/config"
 	"github.com/expgo/factory"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,17 +22,40 @@ const (
 )
 
 type logger struct {
-	base        *zap.Logger
+	base        atomic.Pointer[zap.Logger]
 	level       zap.AtomicLevel
 	originLevel Level
 	tempTimer   *time.Timer
+	tempUntil   time.Time
 	timerLock   sync.Mutex
-	writer      zapcore.WriteSyncer
+	writer      atomic.Pointer[zapcore.WriteSyncer]
+	sinks       atomic.Pointer[[]Sink]
+	releases    []func() error // release the sinks of the current zap logger
+	hooks       hookList
+	buildLock   sync.Mutex
+	sampled     atomic.Uint64
+	dropped     atomic.Uint64
+	dedup       dedup
 
 	typePath string
 	cfgPath  string
 	cfg      *Config
+	active   atomic.Pointer[Config] // config the current sinks were built from
 	once     sync.Once
+
+	// root is the registered logger a With or Named logger was derived from.
+	// Derived loggers share its level, hooks and sinks.
+	root    *logger
+	names   []string
+	fields  []zap.Field
+	derived atomic.Pointer[derivedBase]
+}
+
+// derivedBase caches the zap logger of a derived logger together with the
+// root zap logger it was built from, so it is rebuilt after a reload.
+type derivedBase struct {
+	src  *zap.Logger
+	base *zap.Logger
 }
 
 type ITemporarySetLevel interface {
@@ -43,110 +67,330 @@ func fullCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEnc
 }
 
 func (l *logger) init() {
+	if l.root != nil {
+		l.root.init()
+		return
+	}
+
 	l.once.Do(func() {
-		if l.cfg == nil {
-			if len(l.cfgPath) == 0 {
-				panic("cfgPath or cfg must set one")
-			}
+		if l.cfg == nil && len(l.cfgPath) == 0 {
+			panic("cfgPath or cfg must set one")
+		}
 
-			cfg := factory.New[Config]()
-			if err := config.GetConfig(cfg, l.cfgPath); err != nil {
+		if err := l.build(); err != nil {
+			// keep the program running on a bad config, Reload reports the
+			// error again until the config is fixed
+			_, _ = fmt.Fprintf(os.Stderr, "log: %s: %v, writing to stderr\n", l.typePath, err)
+			if err = l.buildStderr(); err != nil {
 				panic(err)
 			}
-
-			l.cfg = cfg
 		}
+	})
+}
 
-		cfg := l.cfg
-		l.level = zap.NewAtomicLevelAt(cfg.GetZapLevelByType(l.typePath).ToZapLevel())
-
-		ec := zapcore.EncoderConfig{
-			TimeKey:        "time",
-			LevelKey:       "level",
-			NameKey:        "logger",
-			CallerKey:      "caller",
-			FunctionKey:    zapcore.OmitKey,
-			MessageKey:     "msg",
-			StacktraceKey:  "stack",
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeTime:     zapcore.TimeEncoderOfLayout("2006-01-02T15:04:05.000000"),
-			EncodeDuration: zapcore.StringDurationEncoder,
-			EncodeCaller:   fullCallerEncoder,
+// loadConfig returns the config the logger is built from. Loggers created with
+// a config path re-read their section on every call, so a rebuild picks up
+// values changed through config.SetConfig or config.SetValue, and overlay the
+// environment variables of the section on it.
+func (l *logger) loadConfig() (*Config, error) {
+	if len(l.cfgPath) == 0 {
+		if err := l.cfg.Validate(); err != nil {
+			return nil, err
 		}
+		return l.cfg, nil
+	}
 
-		cores := []zapcore.Core{}
-		writers := []zapcore.WriteSyncer{}
+	cfg := factory.New[Config]()
+	if err := config.GetConfig(cfg, l.cfgPath); err != nil {
+		return nil, err
+	}
 
-		if cfg.Console.Stream != ConsoleNo {
+	if err := cfg.applyEnv(envPrefix(l.cfgPath)); err != nil {
+		return nil, err
+	}
 
-			if cfg.Console.Encoder == EncoderText {
-				ec.EncodeLevel = zapcore.LowercaseColorLevelEncoder
-			} else {
-				ec.EncodeLevel = zapcore.LowercaseLevelEncoder
-			}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config '%s': %w", l.cfgPath, err)
+	}
 
-			consoleWriter := zapcore.Lock(os.Stdout)
-			if cfg.Console.Stream == ConsoleStderr {
-				consoleWriter = zapcore.Lock(os.Stderr)
-			}
+	return cfg, nil
+}
 
-			consoleEncoder := zapcore.NewConsoleEncoder(ec)
-			if cfg.Console.Encoder == EncoderJson {
-				consoleEncoder = zapcore.NewJSONEncoder(ec)
-			}
+// build creates the zap logger from the current config and swaps it in. Calls
+// already holding the previous zap logger finish writing through it.
+func (l *logger) build() error {
+	l.buildLock.Lock()
+	defer l.buildLock.Unlock()
 
-			consoleCore := zapcore.NewCore(consoleEncoder, consoleWriter, &l.level)
-			cores = append(cores, consoleCore)
+	cfg, err := l.loadConfig()
+	if err != nil {
+		return err
+	}
 
-			writers = append(writers, consoleWriter)
-		}
+	return l.buildConfig(cfg)
+}
+
+// buildStderr builds the logger from the default config writing to stderr, for
+// a logger whose config fails to build.
+func (l *logger) buildStderr() error {
+	l.buildLock.Lock()
+	defer l.buildLock.Unlock()
+
+	cfg := factory.New[Config]()
+	cfg.Console.Stream = ConsoleStderr
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	return l.buildConfig(cfg)
+}
+
+// buildConfig creates the zap logger from cfg and swaps it in. The caller
+// holds the build lock.
+func (l *logger) buildConfig(cfg *Config) error {
+	cores := []zapcore.Core{}
+	writers := []zapcore.WriteSyncer{}
+	releases := []func() error{}
+
+	sinks := cfg.GetSinks()
+	if closed.Load() {
+		sinks = []Sink{{Type: SinkTypeConsole, Stream: ConsoleStderr}}
+	}
 
-		if len(cfg.File.Filename) > 0 {
-			ec.EncodeLevel = zapcore.LowercaseLevelEncoder
-
-			fileWriter := zapcore.AddSync(&lumberjack.Logger{
-				Filename:   cfg.File.Filename,
-				MaxSize:    cfg.File.MaxSize,
-				MaxAge:     cfg.File.MaxAge,
-				MaxBackups: cfg.File.MaxBackups,
-				LocalTime:  cfg.File.LocalTime,
-				Compress:   cfg.File.Compress,
-			})
-
-			fileEncoder := zapcore.NewJSONEncoder(ec)
-			if cfg.File.Encoder == EncoderText {
-				fileEncoder = zapcore.NewConsoleEncoder(ec)
+	for _, sink := range sinks {
+		core, writer, release, err := newSinkCore(&sink, &cfg.Encoding, &l.level, &l.dropped)
+		if err != nil {
+			for _, release := range releases {
+				_ = release()
 			}
+			return err
+		}
 
-			fileCore := zapcore.NewCore(fileEncoder, fileWriter, &l.level)
-			cores = append(cores, fileCore)
-			writers = append(writers, fileWriter)
+		if cfg.Stacktrace.Trim {
+			core = newStackTrimCore(core)
 		}
 
-		l.base = zap.New(zapcore.NewTee(cores...))
-		l.writer = zapcore.NewMultiWriteSyncer(writers...)
+		cores = append(cores, core)
+		writers = append(writers, writer)
+		releases = append(releases, release)
+	}
 
-		name := cfg.GetName(l.typePath)
-		if len(name) > 0 {
-			l.base = l.base.Named(name)
+	cfgLevel := cfg.GetZapLevelByType(l.typePath)
+	if l.base.Load() == nil {
+		l.level = zap.NewAtomicLevelAt(cfgLevel.ToZapLevel())
+	} else {
+		l.timerLock.Lock()
+		if l.tempTimer != nil {
+			l.originLevel = cfgLevel
+		} else {
+			l.level.SetLevel(cfgLevel.ToZapLevel())
 		}
+		l.timerLock.Unlock()
+	}
 
-		options := []zap.Option{}
-		options = append(options, zap.AddCallerSkip(2))
-		options = append(options, zap.WithCaller(cfg.WithCaller))
+	core := zapcore.NewTee(cores...)
+	if sampling := cfg.GetSamplingByType(l.typePath); sampling != nil {
+		core = l.newSamplerCore(core, sampling)
+	}
 
-		l.base = l.base.WithOptions(options...)
-	})
+	base := zap.New(newHookCore(core, l))
+	writer := zapcore.NewMultiWriteSyncer(writers...)
+
+	name := cfg.GetName(l.typePath)
+	if len(name) > 0 {
+		base = base.Named(name)
+	}
+
+	options := []zap.Option{}
+	options = append(options, zap.AddCallerSkip(2))
+	options = append(options, zap.WithCaller(cfg.GetWithCallerByType(l.typePath)))
+	if cfg.Development {
+		options = append(options, zap.Development())
+	}
+	if stackLevel := cfg.GetStacktraceLevelByType(l.typePath); stackLevel != LevelInvalid {
+		options = append(options, zap.AddStacktrace(stackLevel.ToZapLevel()))
+	}
+
+	base = base.WithOptions(options...)
+
+	oldReleases := l.releases
+	l.releases = releases
+	l.writer.Store(&writer)
+	l.sinks.Store(&sinks)
+	l.active.Store(cfg)
+	l.base.Store(base)
+	l.setDedupWindow(cfg.GetDedupWindowByType(l.typePath))
+
+	var releaseErr error
+	for _, release := range oldReleases {
+		releaseErr = multierr.Append(releaseErr, release())
+	}
+
+	return releaseErr
+}
+
+// close flushes the logger and swaps its sinks for stderr, releasing the
+// files and async queues of the old sinks.
+func (l *logger) close() error {
+	l.timerLock.Lock()
+	rollback := l.tempTimer != nil
+	l.timerLock.Unlock()
+	if rollback {
+		l.rollbackLevel()
+	}
+
+	base := l.base.Load()
+	if base == nil {
+		// not built yet, it will be built on stderr
+		return nil
+	}
+
+	l.flushDedup()
+
+	return multierr.Append(base.Sync(), l.build())
+}
+
+// info describes the logger for List.
+func (l *logger) info() LoggerInfo {
+	info := LoggerInfo{
+		TypePath:   l.typePath,
+		ConfigPath: l.cfgPath,
+		Level:      l.Level(),
+	}
+
+	l.timerLock.Lock()
+	if l.tempTimer != nil {
+		until := l.tempUntil
+		info.TemporaryUntil = &until
+	}
+	l.timerLock.Unlock()
+
+	if sinks := l.sinks.Load(); sinks != nil {
+		info.Sinks = append([]Sink(nil), *sinks...)
+	}
+
+	return info
+}
+
+// getBase returns the zap logger to write through, applying the names and
+// fields of a derived logger on top of its root.
+func (l *logger) getBase() *zap.Logger {
+	if l.root == nil {
+		return l.base.Load()
+	}
+
+	src := l.root.base.Load()
+	if d := l.derived.Load(); d != nil && d.src == src {
+		return d.base
+	}
+
+	base := src
+	for _, name := range l.names {
+		base = base.Named(name)
+	}
+	base = base.With(l.fields...)
+
+	l.derived.Store(&derivedBase{src: src, base: base})
+	return base
+}
+
+// getActive returns the config the current sinks were built from.
+func (l *logger) getActive() *Config {
+	if l.root != nil {
+		return l.root.active.Load()
+	}
+
+	return l.active.Load()
+}
+
+// With returns a logger carrying the given key-value pairs on every entry. The
+// pairs are treated as they are in Logw. The returned logger shares the level,
+// hooks and sinks of this one.
+func (l *logger) With(keysAndValues ...interface{}) Logger {
+	l.init()
+
+	root := l
+	if l.root != nil {
+		root = l.root
+	}
+
+	fields := make([]zap.Field, 0, len(l.fields)+len(keysAndValues))
+	fields = append(fields, l.fields...)
+	fields = append(fields, l.sweetenFields(keysAndValues)...)
+
+	return &logger{
+		typePath: l.typePath,
+		root:     root,
+		names:    l.names,
+		fields:   fields,
+	}
+}
+
+// Named returns a logger with the name segment appended to its logger name.
+// The returned logger shares the level, hooks and sinks of this one.
+func (l *logger) Named(name string) Logger {
+	l.init()
+
+	root := l
+	if l.root != nil {
+		root = l.root
+	}
+
+	names := make([]string, 0, len(l.names)+1)
+	names = append(names, l.names...)
+	names = append(names, name)
+
+	return &logger{
+		typePath: l.typePath,
+		root:     root,
+		names:    names,
+		fields:   l.fields,
+	}
+}
+
+// Reload rebuilds the logger from its config section, keeping the hooks added
+// to it. An active temporary level stays in effect until it expires.
+func (l *logger) Reload() error {
+	if l.root != nil {
+		return l.root.Reload()
+	}
+
+	l.init()
+
+	return l.build()
 }
 
 func (l *logger) Writer() io.Writer {
+	if l.root != nil {
+		return l.root.Writer()
+	}
+
 	l.init()
 
-	return l.writer
+	return writerFunc(func(p []byte) (int, error) {
+		return (*l.writer.Load()).Write(p)
+	})
+}
+
+// Stats returns the counters of the logger, shared with the loggers derived
+// from it.
+func (l *logger) Stats() Stats {
+	if l.root != nil {
+		return l.root.Stats()
+	}
+
+	return Stats{
+		Sampled: l.sampled.Load(),
+		Dropped: l.dropped.Load(),
+	}
 }
 
 // Level reports the minimum enabled level for this logger.
 func (l *logger) Level() Level {
+	if l.root != nil {
+		return l.root.Level()
+	}
+
 	l.init()
 
 	result := Level(l.level.Level())
@@ -159,6 +403,11 @@ func (l *logger) Level() Level {
 
 // SetLevel set the log's level
 func (l *logger) SetLevel(level Level) {
+	if l.root != nil {
+		l.root.SetLevel(level)
+		return
+	}
+
 	l.init()
 
 	l.level.SetLevel(level.ToZapLevel())
@@ -177,6 +426,11 @@ func (l *logger) rollbackLevel() {
 }
 
 func (l *logger) TemporarySetLevel(level Level, d time.Duration) {
+	if l.root != nil {
+		l.root.TemporarySetLevel(level, d)
+		return
+	}
+
 	l.timerLock.Lock()
 	defer l.timerLock.Unlock()
 
@@ -188,6 +442,7 @@ func (l *logger) TemporarySetLevel(level Level, d time.Duration) {
 	}
 
 	if d > 0 {
+		l.tempUntil = time.Now().Add(d)
 		l.tempTimer = time.AfterFunc(d, func() {
 			l.rollbackLevel()
 		})
@@ -196,13 +451,24 @@ func (l *logger) TemporarySetLevel(level Level, d time.Duration) {
 	l.SetLevel(level)
 }
 
-func (l *logger) AddHook(f func(level Level, t time.Time, name string, msg string)) {
-	l.init()
+// AddHook adds a hook receiving the level, time, logger name and message of
+// every written entry. Use AddEntryHook to also see fields, caller and stack.
+// The returned func removes the hook.
+func (l *logger) AddHook(f func(level Level, t time.Time, name string, msg string)) (remove func()) {
+	return l.AddEntryHook(func(entry Entry) {
+		f(entry.Level, entry.Time, entry.LoggerName, entry.Message)
+	})
+}
 
-	l.base = l.base.WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
-		f(Level(entry.Level), entry.Time, entry.LoggerName, entry.Message)
-		return nil
-	}))
+// AddEntryHook adds a hook receiving every written entry with its fields,
+// caller and stack. It is safe to call while other goroutines are logging.
+// The returned func removes the hook.
+func (l *logger) AddEntryHook(f EntryHook) (remove func()) {
+	if l.root != nil {
+		return l.root.AddEntryHook(f)
+	}
+
+	return l.hooks.add(f)
 }
 
 // Log logs the provided arguments at provided level.
@@ -356,10 +622,60 @@ func (l *logger) Fatalw(msg string, keysAndValues ...interface{}) {
 	l.log(zapcore.FatalLevel, msg, nil, keysAndValues)
 }
 
+// LogCtx logs a message at provided level with the key-value pairs extracted
+// from ctx followed by the variadic ones. The pairs are treated as they are in
+// With.
+func (l *logger) LogCtx(ctx context.Context, lvl Level, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, lvl.ToZapLevel(), msg, keysAndValues)
+}
+
+// DebugCtx logs a message at [DebugLevel] with the key-value pairs extracted
+// from ctx followed by the variadic ones.
+func (l *logger) DebugCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, zapcore.DebugLevel, msg, keysAndValues)
+}
+
+// InfoCtx logs a message at [InfoLevel] with the key-value pairs extracted
+// from ctx followed by the variadic ones.
+func (l *logger) InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, zapcore.InfoLevel, msg, keysAndValues)
+}
+
+// WarnCtx logs a message at [WarnLevel] with the key-value pairs extracted
+// from ctx followed by the variadic ones.
+func (l *logger) WarnCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, zapcore.WarnLevel, msg, keysAndValues)
+}
+
+// ErrorCtx logs a message at [ErrorLevel] with the key-value pairs extracted
+// from ctx followed by the variadic ones.
+func (l *logger) ErrorCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, zapcore.ErrorLevel, msg, keysAndValues)
+}
+
+// DPanicCtx logs a message at [DPanicLevel] with the key-value pairs extracted
+// from ctx followed by the variadic ones. In development, the logger then
+// panics. (See [DPanicLevel] for details.)
+func (l *logger) DPanicCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, zapcore.DPanicLevel, msg, keysAndValues)
+}
+
+// PanicCtx logs a message with the key-value pairs extracted from ctx followed
+// by the variadic ones, then panics.
+func (l *logger) PanicCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, zapcore.PanicLevel, msg, keysAndValues)
+}
+
+// FatalCtx logs a message with the key-value pairs extracted from ctx followed
+// by the variadic ones, then calls os.Exit.
+func (l *logger) FatalCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logCtx(ctx, zapcore.FatalLevel, msg, keysAndValues)
+}
+
 // Logln logs a message at provided level.
 // Spaces are always added between arguments.
-func (l *logger) Logln(lvl zapcore.Level, args ...interface{}) {
-	l.logln(lvl, args, nil)
+func (l *logger) Logln(lvl Level, args ...interface{}) {
+	l.logln(lvl.ToZapLevel(), args, nil)
 }
 
 // Debugln logs a message at [DebugLevel].
@@ -408,33 +724,65 @@ func (l *logger) Fatalln(args ...interface{}) {
 // Sync flushes any buffered log entries.
 func (l *logger) Sync() error {
 	l.init()
-	return l.base.Sync()
+	return l.getBase().Sync()
 }
 
 // log message with Sprint, Sprintf, or neither.
 func (l *logger) log(lvl zapcore.Level, template string, fmtArgs []interface{}, context []interface{}) {
 	l.init()
+	base := l.getBase()
 	// If logging at this level is completely disabled, skip the overhead of
 	// string formatting.
-	if lvl < zap.DPanicLevel && !l.base.Core().Enabled(lvl) {
+	if lvl < zap.DPanicLevel && !base.Core().Enabled(lvl) {
+		return
+	}
+
+	msg, ok := l.dedupMessage(lvl, template, func() string {
+		return getMessage(template, fmtArgs)
+	})
+	if !ok {
 		return
 	}
 
-	msg := getMessage(template, fmtArgs)
-	if ce := l.base.Check(lvl, msg); ce != nil {
+	if ce := base.Check(lvl, msg); ce != nil {
 		ce.Write(l.sweetenFields(context)...)
 	}
 }
 
+// logCtx message with the key-value pairs extracted from ctx. The extractors
+// only run when the level is enabled.
+func (l *logger) logCtx(ctx context.Context, lvl zapcore.Level, msg string, keysAndValues []interface{}) {
+	l.init()
+	base := l.getBase()
+	if lvl < zap.DPanicLevel && !base.Core().Enabled(lvl) {
+		return
+	}
+
+	if _, ok := l.dedupMessage(lvl, msg, func() string { return msg }); !ok {
+		return
+	}
+
+	if ce := base.Check(lvl, msg); ce != nil {
+		ce.Write(l.sweetenFields(append(contextKeysAndValues(ctx), keysAndValues...))...)
+	}
+}
+
 // logln message with Sprintln
 func (l *logger) logln(lvl zapcore.Level, fmtArgs []interface{}, context []interface{}) {
 	l.init()
-	if lvl < zap.DPanicLevel && !l.base.Core().Enabled(lvl) {
+	base := l.getBase()
+	if lvl < zap.DPanicLevel && !base.Core().Enabled(lvl) {
+		return
+	}
+
+	msg, ok := l.dedupMessage(lvl, "", func() string {
+		return getMessageln(fmtArgs)
+	})
+	if !ok {
 		return
 	}
 
-	msg := getMessageln(fmtArgs)
-	if ce := l.base.Check(lvl, msg); ce != nil {
+	if ce := base.Check(lvl, msg); ce != nil {
 		ce.Write(l.sweetenFields(context)...)
 	}
 }
@@ -490,7 +838,7 @@ func (l *logger) sweetenFields(args []interface{}) []zap.Field {
 				seenError = true
 				fields = append(fields, zap.Error(err))
 			} else {
-				l.base.Error(_multipleErrMsg, zap.Error(err))
+				l.getBase().Error(_multipleErrMsg, zap.Error(err))
 			}
 			i++
 			continue
@@ -498,7 +846,7 @@ func (l *logger) sweetenFields(args []interface{}) []zap.Field {
 
 		// Make sure this element isn't a dangling key.
 		if i == len(args)-1 {
-			l.base.Error(_oddNumberErrMsg, zap.Any("ignored", args[i]))
+			l.getBase().Error(_oddNumberErrMsg, zap.Any("ignored", args[i]))
 			break
 		}
 
@@ -519,11 +867,17 @@ func (l *logger) sweetenFields(args []interface{}) []zap.Field {
 
 	// If we encountered any invalid key-value pairs, log an error.
 	if len(invalid) > 0 {
-		l.base.Error(_nonStringKeyErrMsg, zap.Array("invalid", invalid))
+		l.getBase().Error(_nonStringKeyErrMsg, zap.Array("invalid", invalid))
 	}
 	return fields
 }
 
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
 type invalidPair struct {
 	position   int
 	key, value interface{}
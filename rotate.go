@@ -0,0 +1,234 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// timeRotateWriter writes to one file per period, named after the period
+// start. Inside a period lumberjack still rotates the file by size. Retention
+// covers every file of the writer: the period files and their size backups.
+type timeRotateWriter struct {
+	lock    sync.Mutex
+	file    FileLog
+	current *lumberjack.Logger
+	size    int64 // bytes in the current file, to notice lumberjack's size rotations
+	period  time.Time
+	now     func() time.Time
+	pattern *regexp.Regexp
+}
+
+func newTimeRotateWriter(f *FileLog) *timeRotateWriter {
+	ext := filepath.Ext(f.Filename)
+	prefix := filepath.Base(strings.TrimSuffix(f.Filename, ext))
+
+	return &timeRotateWriter{
+		file:    *f,
+		now:     time.Now,
+		pattern: regexp.MustCompile(`^` + regexp.QuoteMeta(prefix) + `-\d{4}-\d{2}-\d{2}.*` + regexp.QuoteMeta(ext) + `(\.gz)?$`),
+	}
+}
+
+func (w *timeRotateWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := w.now()
+	period := w.periodStart(now)
+	if w.current == nil || !period.Equal(w.period) {
+		w.openPeriod(now, period)
+	}
+
+	rotating := w.size+int64(len(p)) > w.maxBytes()
+	n, err := w.current.Write(p)
+	if rotating && err == nil {
+		w.size = 0
+		w.removeExpired(now, w.current.Filename)
+	}
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Rotate starts a new size backup of the current period file.
+func (w *timeRotateWriter) Rotate() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.current == nil {
+		return nil
+	}
+
+	if err := w.current.Rotate(); err != nil {
+		return err
+	}
+
+	w.size = 0
+	w.removeExpired(w.now(), w.current.Filename)
+	return nil
+}
+
+func (w *timeRotateWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.current == nil {
+		return nil
+	}
+
+	err := w.current.Close()
+	w.current = nil
+	return err
+}
+
+// maxBytes mirrors the size limit lumberjack applies to the current file.
+func (w *timeRotateWriter) maxBytes() int64 {
+	if w.file.MaxSize <= 0 {
+		return 100 * 1024 * 1024
+	}
+
+	return int64(w.file.MaxSize) * 1024 * 1024
+}
+
+func (w *timeRotateWriter) periodStart(t time.Time) time.Time {
+	if !w.file.LocalTime {
+		t = t.UTC()
+	}
+
+	if w.file.Rotation == RotationHourly {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (w *timeRotateWriter) periodFilename(period time.Time) string {
+	layout := "2006-01-02"
+	if w.file.Rotation == RotationHourly {
+		layout = "2006-01-02-15"
+	}
+
+	ext := filepath.Ext(w.file.Filename)
+	return strings.TrimSuffix(w.file.Filename, ext) + "-" + period.Format(layout) + ext
+}
+
+// openPeriod switches to the file of period and removes expired files. The
+// finished file is compressed in the background. Size rotations inside the
+// period remove expired files too, see Write and Rotate.
+func (w *timeRotateWriter) openPeriod(now time.Time, period time.Time) {
+	finished := ""
+	if w.current != nil {
+		finished = w.current.Filename
+		_ = w.current.Close()
+	}
+
+	w.period = period
+	w.current = &lumberjack.Logger{
+		Filename:  w.periodFilename(period),
+		MaxSize:   w.file.MaxSize,
+		LocalTime: w.file.LocalTime,
+		Compress:  w.file.Compress,
+	}
+
+	w.size = 0
+	if info, err := os.Stat(w.current.Filename); err == nil {
+		w.size = info.Size()
+	}
+
+	if w.file.Compress && len(finished) > 0 {
+		go func() {
+			if err := compressLogFile(finished); err == nil {
+				_ = os.Remove(finished)
+			}
+		}()
+	}
+
+	w.removeExpired(now, w.current.Filename)
+}
+
+// removeExpired applies MaxAge and MaxBackups to all files of the writer except
+// the current one.
+func (w *timeRotateWriter) removeExpired(now time.Time, current string) {
+	if w.file.MaxAge <= 0 && w.file.MaxBackups <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(w.file.Filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type oldFile struct {
+		path    string
+		modTime time.Time
+	}
+
+	files := []oldFile{}
+	for _, entry := range entries {
+		if entry.IsDir() || !w.pattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if path == current {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, oldFile{path: path, modTime: info.ModTime()})
+	}
+
+	// period stamps sort by name, newest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path > files[j].path
+	})
+
+	cutoff := now.Add(-time.Duration(w.file.MaxAge) * 24 * time.Hour)
+	for i, f := range files {
+		if (w.file.MaxBackups > 0 && i >= w.file.MaxBackups) || (w.file.MaxAge > 0 && f.modTime.Before(cutoff)) {
+			_ = os.Remove(f.path)
+		}
+	}
+}
+
+// compressLogFile writes a gzip copy of src next to it.
+func compressLogFile(src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(src+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(src + ".gz")
+		return err
+	}
+
+	if err = gz.Close(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(src + ".gz")
+		return err
+	}
+
+	return out.Close()
+}
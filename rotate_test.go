@@ -0,0 +1,75 @@
+package log
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimeRotate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC)
+
+	w := newTimeRotateWriter(&FileLog{
+		Filename:   filepath.Join(dir, "app.log"),
+		MaxBackups: 1,
+		Rotation:   RotationDaily,
+	})
+	w.now = func() time.Time { return now }
+
+	_, err := w.Write([]byte("day 1\n"))
+	assert.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	_, err = w.Write([]byte("day 2\n"))
+	assert.NoError(t, err)
+
+	now = now.Add(24 * time.Hour)
+	_, err = w.Write([]byte("day 3\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	buf, err := os.ReadFile(filepath.Join(dir, "app-2026-10-20.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "day 3\n", string(buf))
+
+	_, err = os.Stat(filepath.Join(dir, "app-2026-10-18.log"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "app-2026-10-19.log"))
+	assert.NoError(t, err)
+}
+
+func TestHourlyRotateFilename(t *testing.T) {
+	w := newTimeRotateWriter(&FileLog{Filename: "log/app.log", Rotation: RotationHourly, LocalTime: false})
+
+	period := w.periodStart(time.Date(2026, 10, 18, 15, 42, 0, 0, time.UTC))
+	assert.Equal(t, "log/app-2026-10-18-15.log", w.periodFilename(period))
+}
+
+func TestTimeRotateSizeBackups(t *testing.T) {
+	dir := t.TempDir()
+
+	w := newTimeRotateWriter(&FileLog{
+		Filename:   filepath.Join(dir, "app.log"),
+		MaxSize:    1,
+		MaxBackups: 1,
+		Rotation:   RotationDaily,
+	})
+
+	chunk := make([]byte, 600*1024)
+	for i := 0; i < 4; i++ {
+		_, err := w.Write(chunk)
+		assert.NoError(t, err)
+		// lumberjack names backups by millisecond
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.NoError(t, w.Rotate())
+	assert.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	// the period file plus one backup
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultSampling matches the value tags of Sampling. Sampling entries are map
+// values, which do not get the tag defaults, and fall back to it for empty
+// values.
+var defaultSampling = Sampling{
+	Initial:    100,
+	Thereafter: 100,
+	Tick:       time.Second,
+}
+
+// newSamplerCore wraps core with the sampling, counting the dropped entries.
+func (l *logger) newSamplerCore(core zapcore.Core, sampling *Sampling) zapcore.Core {
+	initial := sampling.Initial
+	if initial <= 0 {
+		initial = defaultSampling.Initial
+	}
+
+	thereafter := sampling.Thereafter
+	if thereafter <= 0 {
+		thereafter = defaultSampling.Thereafter
+	}
+
+	tick := sampling.Tick
+	if tick <= 0 {
+		tick = defaultSampling.Tick
+	}
+
+	hook := zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped > 0 {
+			l.sampled.Add(1)
+		}
+	})
+
+	return zapcore.NewSamplerWithOptions(core, tick, initial, thereafter, hook)
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"github.com/expgo/config"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestSampling(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"console": map[string]any{"stream": "no"},
+		"file":    map[string]any{"filename": filepath.Join(t.TempDir(), "sampling.log")},
+		"sampling": map[string]any{
+			"*Struct": map[string]any{"initial": 2, "thereafter": 5, "tick": "1h"},
+		},
+	}, "sampling"))
+
+	structLog := LogWithConfigPath[MyLogStruct]("sampling")
+	log := LogWithConfigPath[MyLog]("sampling")
+
+	structMsgs, msgs := 0, 0
+	structLog.AddEntryHook(func(entry Entry) {
+		structMsgs++
+	})
+	log.AddEntryHook(func(entry Entry) {
+		msgs++
+	})
+
+	for i := 0; i < 12; i++ {
+		structLog.Infof("hello %d", 0)
+		log.Info("hello")
+	}
+
+	// 1, 2, 7 and 12 are written
+	assert.Equal(t, 4, structMsgs)
+	assert.Equal(t, Stats{Sampled: 8}, structLog.Stats())
+	assert.Equal(t, Stats{Sampled: 8}, structLog.With("k", "v").Stats())
+	assert.Equal(t, 12, msgs)
+	assert.Equal(t, Stats{}, log.Stats())
+}
+
+func TestSamplingDefaults(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"console": map[string]any{"stream": "no"},
+		"file":    map[string]any{"filename": filepath.Join(t.TempDir(), "sampling.log")},
+		"sampling": map[string]any{
+			"*Struct": map[string]any{"tick": "1h"},
+			"*MyLog":  map[string]any{"initial": 2, "tick": "1h"},
+		},
+	}, "sampling_defaults"))
+
+	structLog := LogWithConfigPath[MyLogStruct]("sampling_defaults")
+	log := LogWithConfigPath[MyLog]("sampling_defaults")
+
+	structMsgs, msgs := 0, 0
+	structLog.AddEntryHook(func(entry Entry) {
+		structMsgs++
+	})
+	log.AddEntryHook(func(entry Entry) {
+		msgs++
+	})
+
+	for i := 0; i < 6; i++ {
+		structLog.Info("hello")
+		log.Info("hello")
+	}
+
+	// initial and thereafter default to 100
+	assert.Equal(t, 6, structMsgs)
+	assert.Equal(t, Stats{}, structLog.Stats())
+	assert.Equal(t, 2, msgs)
+	assert.Equal(t, Stats{Sampled: 4}, log.Stats())
+}
@@ -0,0 +1,112 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+/*
+SignalAction is an enum
+
+	@Enum {
+		debug   // raise all loggers to debug for a while
+		reload  // reopen the files and reload the config
+		rotate  // rotate the files
+	}
+*/
+type SignalAction string
+
+// SignalOptions configures HandleSignals.
+type SignalOptions struct {
+	// Actions maps the handled signals to their action. Nil handles SIGUSR1
+	// with debug and SIGHUP with reload, no signals outside unix.
+	Actions map[os.Signal]SignalAction
+
+	// DebugDuration is how long the debug action keeps the debug level. It
+	// defaults to 10 minutes.
+	DebugDuration time.Duration
+
+	// OnError receives the errors of the reload and rotate actions. They are
+	// written to stderr by default.
+	OnError func(action SignalAction, err error)
+}
+
+// HandleSignals runs the action of a signal whenever the process receives it,
+// until the returned func is called:
+//
+//	debug: TemporarySetLevel("*", LevelDebug, DebugDuration)
+//	reload: closes the log files, they are opened again on the next write, so
+//	files moved away by logrotate are replaced; then Reload
+//	rotate: rotates the log files like reaching their MaxSize
+//
+// The returned func can be called more than once.
+func HandleSignals(opts *SignalOptions) (stop func()) {
+	actions := defaultSignalActions
+	debugDuration := 10 * time.Minute
+	onError := func(action SignalAction, err error) {
+		_, _ = fmt.Fprintf(os.Stderr, "log: signal action %s: %v\n", action, err)
+	}
+
+	if opts != nil {
+		if opts.Actions != nil {
+			actions = opts.Actions
+		}
+		if opts.DebugDuration > 0 {
+			debugDuration = opts.DebugDuration
+		}
+		if opts.OnError != nil {
+			onError = opts.OnError
+		}
+	}
+
+	signals := make([]os.Signal, 0, len(actions))
+	for sig := range actions {
+		signals = append(signals, sig)
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	if len(signals) > 0 {
+		signal.Notify(ch, signals...)
+	}
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				action := actions[sig]
+				if err := runSignalAction(action, debugDuration); err != nil {
+					onError(action, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+func runSignalAction(action SignalAction, debugDuration time.Duration) error {
+	switch action {
+	case SignalActionDebug:
+		return TemporarySetLevel("*", LevelDebug, debugDuration)
+	case SignalActionReload:
+		return multierr.Append(reopenFileSinks(), Reload())
+	case SignalActionRotate:
+		return rotateFileSinks()
+	default:
+		return fmt.Errorf("invalid signal action '%s'", action)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package log
+
+import "os"
+
+// windows, plan9 and js have no SIGUSR1 and SIGHUP, signals are only handled
+// when mapped.
+var defaultSignalActions = map[os.Signal]SignalAction{}
@@ -0,0 +1,84 @@
+//go:build unix
+
+package log
+
+import (
+	"github.com/expgo/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "signal.log")
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"level":   map[string]any{"*": "info"},
+		"console": map[string]any{"stream": "no"},
+		"file":    map[string]any{"filename": filename},
+	}, "signal"))
+
+	log := LogWithConfigPath[MyLogStruct]("signal")
+	log.Info("before")
+
+	errs := make(chan error, 1)
+	stop := HandleSignals(&SignalOptions{
+		Actions: map[os.Signal]SignalAction{
+			syscall.SIGUSR1: SignalActionDebug,
+			syscall.SIGHUP:  SignalActionReload,
+			syscall.SIGUSR2: SignalActionRotate,
+		},
+		DebugDuration: time.Hour,
+		OnError: func(action SignalAction, err error) {
+			errs <- err
+		},
+	})
+	defer stop()
+	defer log.TemporarySetLevel(LevelInfo, 0)
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return log.Level() == LevelDebug
+	}, time.Second, 5*time.Millisecond)
+
+	// a moved file is replaced after reload, and the config is read again
+	assert.NoError(t, os.Rename(filename, filename+".1"))
+	assert.NoError(t, config.SetConfig(map[string]any{
+		"level":   map[string]any{"*": "warn"},
+		"console": map[string]any{"stream": "no"},
+		"file":    map[string]any{"filename": filename},
+	}, "signal"))
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		return log.(*logger).getActive().Level["*"] == LevelWarn
+	}, time.Second, 5*time.Millisecond)
+
+	log.Warn("after reload")
+	buf, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf), "after reload")
+	assert.NotContains(t, string(buf), "before")
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	assert.Eventually(t, func() bool {
+		matches, _ := filepath.Glob(filepath.Join(dir, "signal-*.log*"))
+		return len(matches) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	select {
+	case err := <-errs:
+		t.Fatal(err)
+	default:
+	}
+}
+
+func TestHandleSignalsStopTwice(t *testing.T) {
+	stop := HandleSignals(nil)
+	stop()
+	assert.NotPanics(t, stop)
+}
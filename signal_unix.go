@@ -0,0 +1,13 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultSignalActions = map[os.Signal]SignalAction{
+	syscall.SIGUSR1: SignalActionDebug,
+	syscall.SIGHUP:  SignalActionReload,
+}
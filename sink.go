@@ -0,0 +1,223 @@
+package log
+
+import (
+	"fmt"
+	"github.com/expgo/sync"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// fileSinkKey identifies a shared file sink. Loggers whose FileLog resolves to
+// the same file with the same rotation settings write through one rotator.
+type fileSinkKey struct {
+	filename   string
+	maxSize    int
+	maxAge     int
+	maxBackups int
+	localTime  bool
+	compress   bool
+	rotation   Rotation
+}
+
+// sinkLevel enables an entry only when both the logger level and the floor of
+// the sink allow it, so SetLevel keeps working per type while each sink can
+// drop lower levels.
+type sinkLevel struct {
+	zapcore.LevelEnabler
+	floor zapcore.Level
+}
+
+func (s sinkLevel) Enabled(lvl zapcore.Level) bool {
+	return lvl >= s.floor && s.LevelEnabler.Enabled(lvl)
+}
+
+// newSinkCore builds the core writing to sink, encoding entries with the
+// section encoding overridden by the one of the sink. A file sink is taken
+// from the shared registry, and an async sink starts its writer goroutine;
+// the returned release func undoes both once the core is no longer used.
+// Entries dropped by an async sink are counted in dropped.
+func newSinkCore(sink *Sink, encoding *Encoding, level zapcore.LevelEnabler, dropped *atomic.Uint64) (zapcore.Core, zapcore.WriteSyncer, func() error, error) {
+	sinkLevel := sinkLevel{LevelEnabler: level, floor: sink.GetLevel().ToZapLevel()}
+
+	color := sink.Type == SinkTypeConsole && sink.Encoder == EncoderText
+	merged := defaultEncoding.merge(encoding).merge(sink.Encoding)
+	ec, err := merged.encoderConfig(color)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("sink '%s': %w", sink.GetName(), err)
+	}
+
+	newCore := func(writer zapcore.WriteSyncer, release func() error) (zapcore.Core, zapcore.WriteSyncer, func() error, error) {
+		if sink.Async != nil {
+			aw := newAsyncWriter(writer, sink.Async, dropped)
+			writer = aw
+			releaseWriter := release
+			release = func() error {
+				return multierr.Append(aw.Close(), releaseWriter())
+			}
+		}
+
+		core := zapcore.NewCore(newEncoder(sink.Encoder, ec), writer, sinkLevel)
+		if merged.Caller == CallerFormatFields && merged.CallerKey != omitKey {
+			core = newCallerFieldsCore(core)
+		}
+		return core, writer, release, nil
+	}
+
+	switch sink.Type {
+	case SinkTypeConsole:
+		writer := zapcore.Lock(os.Stdout)
+		if sink.GetStream() == ConsoleStderr {
+			writer = zapcore.Lock(os.Stderr)
+		}
+
+		return newCore(writer, func() error { return nil })
+	case SinkTypeFile:
+		if len(sink.Filename) == 0 {
+			return nil, nil, nil, fmt.Errorf("sink '%s' has no filename", sink.GetName())
+		}
+
+		fs, err := acquireFileSink(sink.fileLog())
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		return newCore(fs, fs.release)
+	default:
+		return nil, nil, nil, fmt.Errorf("sink '%s' has invalid type '%s'", sink.GetName(), sink.Type)
+	}
+}
+
+func newEncoder(encoder Encoder, ec zapcore.EncoderConfig) zapcore.Encoder {
+	if encoder == EncoderJson {
+		return zapcore.NewJSONEncoder(ec)
+	}
+
+	return zapcore.NewConsoleEncoder(ec)
+}
+
+type fileSink struct {
+	zapcore.WriteSyncer
+	key  fileSinkKey
+	w    io.WriteCloser
+	refs int
+}
+
+var fileSinks = map[fileSinkKey]*fileSink{}
+var fileSinksLock = sync.NewMutex()
+
+// acquireFileSink returns the process-wide sink for the file config, creating
+// it on first use. Every call must be paired with a release.
+func acquireFileSink(f *FileLog) (*fileSink, error) {
+	filename, err := filepath.Abs(f.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fileSinkKey{
+		filename:   filename,
+		maxSize:    f.MaxSize,
+		maxAge:     f.MaxAge,
+		maxBackups: f.MaxBackups,
+		localTime:  f.LocalTime,
+		compress:   f.Compress,
+		rotation:   f.Rotation,
+	}
+
+	fileSinksLock.Lock()
+	defer fileSinksLock.Unlock()
+
+	sink, ok := fileSinks[key]
+	if !ok {
+		var w io.WriteCloser
+		if f.Rotation == RotationHourly || f.Rotation == RotationDaily {
+			resolved := *f
+			resolved.Filename = filename
+			w = newTimeRotateWriter(&resolved)
+		} else {
+			w = &lumberjack.Logger{
+				Filename:   filename,
+				MaxSize:    f.MaxSize,
+				MaxAge:     f.MaxAge,
+				MaxBackups: f.MaxBackups,
+				LocalTime:  f.LocalTime,
+				Compress:   f.Compress,
+			}
+		}
+
+		sink = &fileSink{
+			WriteSyncer: zapcore.AddSync(w),
+			key:         key,
+			w:           w,
+		}
+		fileSinks[key] = sink
+	}
+
+	sink.refs++
+
+	return sink, nil
+}
+
+// release drops one reference and closes the file when no logger uses it.
+func (s *fileSink) release() error {
+	fileSinksLock.Lock()
+	defer fileSinksLock.Unlock()
+
+	s.refs--
+	if s.refs > 0 {
+		return nil
+	}
+
+	// closeFileSinks may have dropped the sink and a newer one took the key
+	if fileSinks[s.key] == s {
+		delete(fileSinks, s.key)
+	}
+	return s.w.Close()
+}
+
+// closeFileSinks closes the files still open, whatever their references.
+func closeFileSinks() error {
+	fileSinksLock.Lock()
+	defer fileSinksLock.Unlock()
+
+	var err error
+	for key, sink := range fileSinks {
+		err = multierr.Append(err, sink.w.Close())
+		delete(fileSinks, key)
+	}
+
+	return err
+}
+
+// reopenFileSinks closes the open files, each is opened again by its next
+// write.
+func reopenFileSinks() error {
+	fileSinksLock.Lock()
+	defer fileSinksLock.Unlock()
+
+	var err error
+	for _, sink := range fileSinks {
+		err = multierr.Append(err, sink.w.Close())
+	}
+
+	return err
+}
+
+// rotateFileSinks starts a new backup of every open file.
+func rotateFileSinks() error {
+	fileSinksLock.Lock()
+	defer fileSinksLock.Unlock()
+
+	var err error
+	for _, sink := range fileSinks {
+		if r, ok := sink.w.(interface{ Rotate() error }); ok {
+			err = multierr.Append(err, r.Rotate())
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,190 @@
+//go:build go1.21
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler is a slog.Handler writing records through a registered logger,
+// so slog output follows the levels and sinks configured for the type path.
+type slogHandler struct {
+	l      *logger
+	fields []zap.Field
+	groups []string // groups opened by WithGroup that hold no attrs yet
+}
+
+// NewSlogHandler returns a slog.Handler backed by the logger registered for
+// typePath under the cfgPath config section.
+//
+// Records are enabled by the per-type level of that logger. slog levels map
+// onto the closest Level at or below them, so slog.LevelDebug-4 is still debug.
+// Groups are written as nested objects. Stacks and development mode follow the
+// config of the logger as for its own entries.
+func NewSlogHandler(typePath string, cfgPath string) slog.Handler {
+	return &slogHandler{l: NewWithTypePathAndConfigPath(typePath, cfgPath).(*logger)}
+}
+
+// NewSlogHandlerWithLogger returns a slog.Handler writing through l. l must
+// be a logger created by this package, other Logger implementations are
+// reported as an error.
+func NewSlogHandlerWithLogger(l Logger) (slog.Handler, error) {
+	impl, ok := l.(*logger)
+	if !ok {
+		return nil, fmt.Errorf("slog handler: unsupported logger %T", l)
+	}
+
+	return &slogHandler{l: impl}, nil
+}
+
+func slogLevelToZap(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	h.l.init()
+
+	return h.l.getBase().Core().Enabled(slogLevelToZap(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, record slog.Record) error {
+	h.l.init()
+
+	// the zap logger applies the stacktrace level and development mode, the
+	// extra skip drops the slog frames between the caller and Handle
+	ce := h.l.getBase().WithOptions(zap.AddCallerSkip(1)).Check(slogLevelToZap(record.Level), record.Message)
+	if ce == nil {
+		return nil
+	}
+
+	if !record.Time.IsZero() {
+		ce.Time = record.Time
+	}
+
+	if cfg := h.l.getActive(); cfg != nil && cfg.GetWithCallerByType(h.l.typePath) && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  frame.PC != 0,
+			PC:       frame.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+
+	fields := make([]zap.Field, 0, len(h.fields)+len(h.groups)+record.NumAttrs())
+	fields = append(fields, h.fields...)
+	if record.NumAttrs() > 0 {
+		for _, group := range h.groups {
+			fields = append(fields, zap.Namespace(group))
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			fields = appendSlogAttr(fields, attr)
+			return true
+		})
+	}
+
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := make([]zap.Field, 0, len(h.fields)+len(h.groups)+len(attrs))
+	fields = append(fields, h.fields...)
+	for _, group := range h.groups {
+		fields = append(fields, zap.Namespace(group))
+	}
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+
+	return &slogHandler{l: h.l, fields: fields}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
+	groups = append(groups, name)
+
+	return &slogHandler{l: h.l, fields: h.fields, groups: groups}
+}
+
+// appendSlogAttr converts attr into zap fields following the slog.Handler
+// rules: empty attrs are dropped and groups with an empty key are inlined.
+func appendSlogAttr(fields []zap.Field, attr slog.Attr) []zap.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, attr.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, attr.Value.Duration()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, attr.Value.Float64()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, attr.Value.Int64()))
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, attr.Value.String()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, attr.Value.Time()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, attr.Value.Uint64()))
+	case slog.KindGroup:
+		attrs := attr.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if len(attr.Key) == 0 {
+			for _, a := range attrs {
+				fields = appendSlogAttr(fields, a)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(attr.Key, slogGroup(attrs)))
+	default:
+		if err, ok := attr.Value.Any().(error); ok {
+			return append(fields, zap.NamedError(attr.Key, err))
+		}
+		return append(fields, zap.Any(attr.Key, attr.Value.Any()))
+	}
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zap.Field
+	for _, attr := range g {
+		fields = appendSlogAttr(fields, attr)
+	}
+
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+//go:build go1.21
+
+package log
+
+import (
+	"github.com/expgo/factory"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	filename := filepath.Join(t.TempDir(), "slog.log")
+	cfg := factory.New[Config]()
+	cfg.Console.Stream = ConsoleNo
+	cfg.File.Filename = filename
+	cfg.File.Encoder = EncoderJson
+
+	l := NewWithTypePathAndConfig("github.com/expgo/log.SlogStruct", cfg)
+	handler, err := NewSlogHandlerWithLogger(l)
+	assert.NoError(t, err)
+	sl := slog.New(handler)
+
+	sl.Debug("debug hello")
+	sl.With("k", "v").WithGroup("req").Info("info hello", "id", 1, slog.Group("user", "name", "u1"))
+	sl.WithGroup("empty").Warn("warn hello")
+
+	assert.False(t, sl.Enabled(nil, slog.LevelDebug))
+	l.SetLevel(LevelDebug)
+	assert.True(t, sl.Enabled(nil, slog.LevelDebug))
+
+	assert.NoError(t, l.Sync())
+	buf, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	out := string(buf)
+	assert.NotContains(t, out, "debug hello")
+	assert.Contains(t, out, `"msg":"info hello","k":"v","req":{"id":1,"user":{"name":"u1"}}`)
+	assert.Contains(t, out, `"msg":"warn hello"}`)
+	assert.Contains(t, out, "slog_test.go")
+}
+
+type wrappedLogger struct {
+	Logger
+}
+
+func TestSlogHandlerWithForeignLogger(t *testing.T) {
+	logs = map[logKey]*logger{}
+
+	l := NewWithTypePathAndConfig("github.com/expgo/log.SlogStruct", factory.New[Config]())
+
+	handler, err := NewSlogHandlerWithLogger(wrappedLogger{l})
+	assert.Nil(t, handler)
+	assert.Error(t, err)
+}
+
+func TestSlogStacktrace(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	cfg.Stacktrace.Trim = false
+
+	l := NewWithTypePathAndConfig("github.com/expgo/log.SlogStruct", cfg)
+	handler, err := NewSlogHandlerWithLogger(l)
+	assert.NoError(t, err)
+	sl := slog.New(handler)
+
+	stacks := []string{}
+	defer l.AddEntryHook(func(entry Entry) {
+		stacks = append(stacks, entry.Stack)
+	})()
+
+	sl.Warn("warn hello")
+	sl.Error("error hello")
+
+	assert.Len(t, stacks, 2)
+	assert.Empty(t, stacks[0])
+	assert.True(t, strings.HasPrefix(stacks[1], "github.com/expgo/log.TestSlogStacktrace"), stacks[1])
+}
+
+func TestSlogWithCallerType(t *testing.T) {
+	cfg, filename := newTestConfig(t)
+	cfg.File.Encoder = EncoderJson
+	cfg.WithCallerType = map[string]bool{"*SlogStruct": false}
+
+	l := NewWithTypePathAndConfig("github.com/expgo/log.SlogStruct", cfg)
+	handler, err := NewSlogHandlerWithLogger(l)
+	assert.NoError(t, err)
+	slog.New(handler).Info("no caller")
+
+	out := readLog(t, l, filename)
+	assert.Contains(t, out, `"msg":"no caller"}`)
+	assert.NotContains(t, out, `"caller"`)
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const packagePath = "github.com/expgo/log"
+
+// stackTrimCore removes the frames of this package and of the runtime from the
+// stack of the entries before writing them to the wrapped sink core.
+type stackTrimCore struct {
+	zapcore.Core
+}
+
+func newStackTrimCore(core zapcore.Core) zapcore.Core {
+	return &stackTrimCore{Core: core}
+}
+
+func (c *stackTrimCore) With(fields []zapcore.Field) zapcore.Core {
+	return &stackTrimCore{Core: c.Core.With(fields)}
+}
+
+func (c *stackTrimCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *stackTrimCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if len(ent.Stack) > 0 {
+		ent.Stack = trimStack(ent.Stack)
+	}
+
+	return c.Core.Write(ent, fields)
+}
+
+// trimStack drops frames from a stack formatted by zap, where every frame is a
+// function line followed by a tab indented file:line line.
+func trimStack(stack string) string {
+	lines := strings.Split(stack, "\n")
+	kept := make([]string, 0, len(lines))
+
+	for i := 0; i < len(lines); i += 2 {
+		if isTrimmedFunction(lines[i]) {
+			continue
+		}
+
+		kept = append(kept, lines[i])
+		if i+1 < len(lines) {
+			kept = append(kept, lines[i+1])
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+func isTrimmedFunction(function string) bool {
+	return strings.HasPrefix(function, packagePath+".") ||
+		strings.HasPrefix(function, "runtime.") ||
+		strings.HasPrefix(function, "runtime/")
+}
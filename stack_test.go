@@ -0,0 +1,43 @@
+package log
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStacktrace(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	cfg.Stacktrace.Level["*Struct"] = LevelWarn
+	cfg.Stacktrace.Trim = false
+
+	structLog := LogWithConfig[MyLogStruct](cfg)
+	log := LogWithConfig[MyLog](cfg)
+
+	stacks := []string{}
+	removeStruct := structLog.AddEntryHook(func(entry Entry) {
+		stacks = append(stacks, entry.Stack)
+	})
+	defer removeStruct()
+	removeLog := log.AddEntryHook(func(entry Entry) {
+		stacks = append(stacks, entry.Stack)
+	})
+	defer removeLog()
+
+	structLog.Warn("warn hello")
+	log.Warn("warn hello")
+	log.Error("error hello")
+
+	assert.Len(t, stacks, 3)
+	assert.Contains(t, stacks[0], "github.com/expgo/log.TestStacktrace")
+	assert.Empty(t, stacks[1])
+	assert.NotEmpty(t, stacks[2])
+
+	cfg.Stacktrace.Trim = true
+	assert.NoError(t, Reload())
+	stacks = stacks[:0]
+
+	structLog.Error("error hello")
+	assert.Len(t, stacks, 1)
+	assert.Contains(t, stacks[0], "testing.tRunner")
+	assert.NotContains(t, stacks[0], "github.com/expgo/log.")
+}